@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"durl/internal/formatter"
+	"durl/internal/politeness"
+	"durl/internal/scraper"
+)
+
+// batchRecord is one NDJSON line describing the outcome of a batch target
+type batchRecord struct {
+	Index    int             `json:"index"`
+	Target   string          `json:"target"`
+	Output   string          `json:"output,omitempty"`  // file written when --output is a template
+	Content  json.RawMessage `json:"content,omitempty"` // content for -f json
+	Text     string          `json:"text,omitempty"`    // formatted content for other formats
+	Error    string          `json:"error,omitempty"`
+	Skipped  bool            `json:"skipped,omitempty"` // disallowed by robots.txt, not fetched
+	ExitCode int             `json:"exit_code"`
+}
+
+// batchError summarizes failed targets; it unwraps to the first failure
+// (in input order) so the exit code reflects that target
+type batchError struct {
+	failed int
+	total  int
+	first  error
+}
+
+func (e *batchError) Error() string {
+	return fmt.Sprintf("%d of %d targets failed: %v", e.failed, e.total, e.first)
+}
+
+func (e *batchError) Unwrap() error {
+	return e.first
+}
+
+// runBatch fetches all targets with a bounded worker pool over the shared
+// browser pool. Results are streamed as NDJSON (to stdout or --output), or
+// written one file per target when --output contains {placeholders}.
+// Per-target errors are reported without aborting the batch.
+func runBatch(ctx context.Context, args []string, opts scraper.Options) error {
+	targets, err := readTargets(args)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no targets given")
+	}
+
+	perTarget := strings.Contains(outputFile, "{")
+	if isBinaryFormat(outputFormat) && !perTarget {
+		return fmt.Errorf("-f %s in batch mode requires a per-target --output template (e.g. \"{slug}%s\")", outputFormat, formatter.Extension(outputFormat))
+	}
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" && !perTarget {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	workers := concurrency
+	if workers > len(targets) {
+		workers = len(targets)
+	}
+
+	errs := make([]error, len(targets))
+	jobs := make(chan int)
+	var mu sync.Mutex
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rec, err := runBatchTarget(ctx, i, targets[i], opts, perTarget)
+				errs[i] = err
+				switch {
+				case rec.Skipped:
+					fmt.Fprintf(os.Stderr, "[batch] skipped %s: %v\n", targets[i], err)
+				case err != nil:
+					fmt.Fprintf(os.Stderr, "[batch] %s: %v\n", targets[i], err)
+				}
+
+				mu.Lock()
+				_ = enc.Encode(rec)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	batchErr := &batchError{total: len(targets)}
+	skipped := 0
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, politeness.ErrDisallowed) {
+			skipped++
+		}
+		batchErr.failed++
+		if batchErr.first == nil {
+			batchErr.first = err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "[batch] %d/%d targets succeeded (%d skipped by robots.txt)\n", len(targets)-batchErr.failed, len(targets), skipped)
+	if batchErr.failed > 0 {
+		return batchErr
+	}
+	return nil
+}
+
+// runBatchTarget scrapes and renders one target, returning its NDJSON record
+func runBatchTarget(ctx context.Context, index int, target string, opts scraper.Options, perTarget bool) (batchRecord, error) {
+	rec := batchRecord{Index: index + 1, Target: target}
+	fail := func(err error) (batchRecord, error) {
+		rec.Error = err.Error()
+		rec.Skipped = errors.Is(err, politeness.ErrDisallowed)
+		rec.ExitCode = exitCode(err)
+		return rec, err
+	}
+
+	content, err := scrapeTarget(ctx, target, opts)
+	if err != nil {
+		return fail(err)
+	}
+	rendered, err := renderContent(content)
+	if err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case perTarget:
+		path := expandOutputTemplate(outputFile, index+1, target)
+		if dir := filepath.Dir(path); dir != "." {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fail(fmt.Errorf("failed to create output directory: %w", err))
+			}
+		}
+		if err := os.WriteFile(path, []byte(rendered), 0644); err != nil {
+			return fail(fmt.Errorf("failed to write to file: %w", err))
+		}
+		rec.Output = path
+	case outputFormat == "json" && !headOnly && !include:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(rendered)); err != nil {
+			return fail(fmt.Errorf("failed to encode JSON output: %w", err))
+		}
+		rec.Content = buf.Bytes()
+	default:
+		rec.Text = rendered
+	}
+	return rec, nil
+}
+
+// readTargets collects targets from arguments and --input-file, where "-"
+// reads newline-separated targets from stdin
+func readTargets(args []string) ([]string, error) {
+	sources := append([]string{}, args...)
+	if inputFile != "" {
+		sources = append(sources, inputFile)
+	}
+
+	var targets []string
+	stdinRead := false
+	for i, src := range sources {
+		fromFile := inputFile != "" && i == len(sources)-1
+		switch {
+		case src == "-":
+			if stdinRead {
+				continue
+			}
+			stdinRead = true
+			lines, err := readTargetLines(os.Stdin)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read targets from stdin: %w", err)
+			}
+			targets = append(targets, lines...)
+		case fromFile:
+			f, err := os.Open(src)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open input file: %w", err)
+			}
+			lines, err := readTargetLines(f)
+			f.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read input file: %w", err)
+			}
+			targets = append(targets, lines...)
+		default:
+			targets = append(targets, src)
+		}
+	}
+	return targets, nil
+}
+
+// readTargetLines reads one target per line, skipping blank lines and # comments
+func readTargetLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// expandOutputTemplate fills the --output template placeholders for a target:
+// {index} (1-based), {host} (URL host, or the site name for site queries),
+// {slug} (URL path/query or query text, filesystem-safe) and {site}
+func expandOutputTemplate(tmpl string, index int, target string) string {
+	siteName := site
+	if siteName == "" {
+		siteName = "generic"
+	}
+
+	host, slug := siteName, formatter.Slug(target)
+	if site == "" {
+		if u, err := url.Parse(normalizeURL(target)); err == nil && u.Host != "" {
+			host = formatter.Slug(u.Host)
+			slug = formatter.Slug(strings.TrimPrefix(u.Path, "/") + " " + u.RawQuery)
+		}
+	}
+	if slug == "" {
+		slug = "index"
+	}
+
+	return strings.NewReplacer(
+		"{index}", strconv.Itoa(index),
+		"{host}", host,
+		"{slug}", slug,
+		"{site}", siteName,
+	).Replace(tmpl)
+}
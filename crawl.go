@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"durl/internal/browser"
+	"durl/internal/crawler"
+	"durl/internal/politeness"
+	"durl/internal/sites/generic"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	crawlDepth       int
+	crawlMaxPages    int
+	crawlHosts       []string
+	crawlPathPrefix  string
+	crawlIncludes    []string
+	crawlExcludes    []string
+	crawlDelay       time.Duration
+	crawlConcurrency int
+	crawlOutputDir   string
+	crawlFormat      string
+	crawlLevel       string
+	crawlSelector    string
+	crawlNoRobots    bool
+)
+
+// newCrawlCommand creates the "durl crawl" subcommand
+func newCrawlCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "crawl [URL...]",
+		Short: "Crawl a site by following links within a scope",
+		Long: `crawl fetches the seed URLs, follows links up to --depth within the scope
+(seed hosts by default), and writes each page's extracted content plus a
+manifest.json to the output directory.`,
+		Example: `  # Crawl the docs section two links deep, one request per second per host
+  durl crawl --depth 2 --path-prefix /docs/ -o docs-out https://example.com/docs/
+
+  # JSON output, skip changelog pages
+  durl crawl -f json --exclude '/changelog' --max-pages 50 https://example.com`,
+		Args:         cobra.MinimumNArgs(1),
+		RunE:         runCrawl,
+		SilenceUsage: true,
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&crawlDepth, "depth", 2, "Maximum link depth to follow from the seeds (0 = seeds only)")
+	f.IntVar(&crawlMaxPages, "max-pages", 100, "Maximum number of pages to crawl (-1 for no limit)")
+	f.StringSliceVar(&crawlHosts, "include-host", nil, "Hosts to stay on (default: seed hosts; prefix with '.' to allow subdomains)")
+	f.StringVar(&crawlPathPrefix, "path-prefix", "", "Only follow URLs whose path starts with this prefix")
+	f.StringArrayVar(&crawlIncludes, "include", nil, "Only follow URLs matching this regex (can be used multiple times)")
+	f.StringArrayVar(&crawlExcludes, "exclude", nil, "Never follow URLs matching this regex (can be used multiple times)")
+	f.DurationVar(&crawlDelay, "delay", time.Second, "Minimum delay between requests to the same host (robots.txt Crawl-delay may raise it)")
+	f.BoolVar(&crawlNoRobots, "ignore-robots", false, "Do not fetch or honour robots.txt")
+	f.IntVar(&crawlConcurrency, "concurrency", 2, "Number of pages fetched in parallel")
+	f.StringVarP(&crawlOutputDir, "output", "o", "crawl", "Output directory for pages and manifest.json")
+	f.StringVarP(&crawlFormat, "format", "f", "markdown", "Page output format (markdown, json, html, text)")
+	f.StringVarP(&crawlLevel, "level", "l", "content", "Content extraction level (full, html, body, content, xpath, css, links)")
+	f.StringVarP(&crawlSelector, "selector", "s", "", "Selector for xpath or css level")
+	f.StringVar(&actionsFile, "actions", "", "YAML/JSON list of page actions run on every page before extraction")
+	f.BoolVar(&pierce, "pierce", false, "CSS selectors and whole-page levels include open shadow DOM content")
+	f.BoolVar(&frames, "frames", false, "Selectors also search iframes; html/body/content/full levels inline iframe content")
+	f.BoolVar(&frontMatter, "front-matter", false, "Prepend YAML front matter to markdown pages")
+	f.StringSliceVar(&block, "block", nil, "Block requests: resource types (image, font, media, stylesheet, ...), \"ads\" or URL globs (repeatable)")
+	f.StringVar(&mockFile, "mock", "", "YAML rules file that mocks, aborts or rewrites matching requests")
+	f.StringVar(&harFile, "har", "", "Record all network requests of the crawl to a HAR file")
+	f.BoolVar(&harBodies, "har-bodies", false, "Include response bodies in the HAR file")
+
+	// Fetch and browser settings shared with the root command
+	f.StringSliceVarP(&headers, "header", "H", []string{}, "HTTP headers (can be used multiple times)")
+	f.StringVarP(&waitFor, "wait-for", "w", generic.DefaultWait, "Wait strategy (load, networkidle, element, time, js, text, url), combined with '+' like element+networkidle")
+	f.StringArrayVarP(&waitTargets, "wait-target", "T", nil, "Wait target: selector (element), milliseconds (time), expression (js), text (text) or URL regex (url); repeat for composite strategies")
+	f.DurationVarP(&timeout, "timeout", "t", 30*time.Second, "Per-page timeout duration")
+	f.BoolVar(&showUI, "showui", false, "Show browser UI (disable headless mode)")
+	f.StringVarP(&proxyURL, "proxy", "p", os.Getenv("DURL_PROXY"), "Proxy URL, defaults to DURL_PROXY env var")
+	f.StringVarP(&cookie, "cookie", "b", "", "Cookie file to load (Netscape or JSON), or a literal \"name=value; ...\" string")
+	f.StringVarP(&cookieJar, "cookie-jar", "c", "", "File to save cookies to after the crawl")
+	f.StringVar(&loginFile, "login", "", "YAML/JSON login form definition run before the crawl")
+	f.StringVar(&userDataDir, "user-data-dir", "", "Persistent browser profile directory")
+
+	return cmd
+}
+
+func runCrawl(cmd *cobra.Command, args []string) error {
+	validFormats := map[string]bool{"markdown": true, "json": true, "html": true, "text": true}
+	if !validFormats[crawlFormat] {
+		return fmt.Errorf("invalid crawl output format: %s", crawlFormat)
+	}
+	if crawlDepth < 0 {
+		return fmt.Errorf("--depth must not be negative")
+	}
+	if harBodies && harFile == "" {
+		return fmt.Errorf("--har-bodies requires --har")
+	}
+	if _, err := generic.ParseWait(waitFor, waitTargets); err != nil {
+		return err
+	}
+	if crawlMaxPages == 0 || crawlMaxPages < -1 {
+		return fmt.Errorf("--max-pages must be positive, or -1 for no limit")
+	}
+	if crawlConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if (crawlLevel == "xpath" || crawlLevel == "css") && crawlSelector == "" {
+		return fmt.Errorf("--selector is required when using '%s' level", crawlLevel)
+	}
+
+	scope := crawler.Scope{Hosts: crawlHosts, PathPrefix: crawlPathPrefix}
+	for _, expr := range crawlIncludes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid --include regex %q: %w", expr, err)
+		}
+		scope.Include = append(scope.Include, re)
+	}
+	for _, expr := range crawlExcludes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid --exclude regex %q: %w", expr, err)
+		}
+		scope.Exclude = append(scope.Exclude, re)
+	}
+
+	pool := browser.NewPool()
+	defer pool.Close()
+
+	opts, err := buildOptions(pool)
+	if err != nil {
+		return err
+	}
+	defer writeHAR(opts.HAR)
+	opts.Method = "GET"
+	opts.Level = crawlLevel
+	opts.Selector = crawlSelector
+	opts.Politeness = politeness.New(politeness.Config{Interval: crawlDelay, IgnoreRobots: crawlNoRobots, ProxyURL: proxyURL})
+
+	seeds := make([]string, len(args))
+	for i, a := range args {
+		seeds[i] = normalizeURL(a)
+	}
+
+	c := crawler.New(crawler.Config{
+		MaxDepth:    crawlDepth,
+		MaxPages:    crawlMaxPages,
+		Concurrency: crawlConcurrency,
+		Scope:       scope,
+		OutputDir:   crawlOutputDir,
+		Format:      crawlFormat,
+	}, opts)
+
+	manifest, err := c.Run(context.Background(), seeds)
+	if err != nil {
+		return err
+	}
+
+	failed, skipped := 0, 0
+	for _, p := range manifest.Pages {
+		switch {
+		case p.Skipped:
+			skipped++
+		case p.Error != "":
+			failed++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "[crawl] %d pages crawled (%d failed, %d skipped by robots.txt), manifest: %s/manifest.json\n",
+		len(manifest.Pages)-skipped, failed, skipped, crawlOutputDir)
+	return nil
+}
@@ -0,0 +1,168 @@
+// Package actions describes scripted page interactions (clicks, typing,
+// scrolling, waits, ...) that run after navigation and before extraction.
+package actions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Action names
+const (
+	Click      = "click"
+	Type       = "type"
+	Press      = "press"
+	Select     = "select"
+	Scroll     = "scroll"
+	Wait       = "wait"
+	Eval       = "eval"
+	Screenshot = "screenshot"
+)
+
+// Script is the list of steps of an --actions file
+type Script struct {
+	Steps []Step
+
+	hash string // content hash of the actions file, for cache keys
+}
+
+// Step is one page action
+type Step struct {
+	Action   string  `yaml:"action"`   // click, type, press, select, scroll, wait, eval, screenshot
+	Selector string  `yaml:"selector"` // CSS selector of the target element
+	Text     string  `yaml:"text"`     // type: text to enter
+	Clear    bool    `yaml:"clear"`    // type: replace the current value instead of appending
+	Key      string  `yaml:"key"`      // press: key name (Enter, Tab, Escape, ArrowDown, ...) or a single character
+	Value    string  `yaml:"value"`    // select: option value or visible text
+	To       string  `yaml:"to"`       // scroll: top or bottom
+	Y        int     `yaml:"y"`        // scroll: pixels to scroll by; default one viewport
+	Repeat   int     `yaml:"repeat"`   // scroll: number of times, pausing ms between them
+	For      string  `yaml:"for"`      // wait: strategy as for --wait-for (element, text, networkidle, ...)
+	Target   Strings `yaml:"target"`   // wait: target(s) as for --wait-target
+	MS       int     `yaml:"ms"`       // wait: milliseconds (without for); scroll: pause between repeats
+	Script   string  `yaml:"script"`   // eval: JavaScript expression or function body
+	Path     string  `yaml:"path"`     // screenshot: output file (.png or .jpg)
+	Optional bool    `yaml:"optional"` // a failure (e.g. a popup that never appears) does not stop the script
+}
+
+// Strings accepts a single YAML string or a list of strings
+type Strings []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Strings) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = Strings{node.Value}
+		return nil
+	}
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// Load reads a YAML or JSON list of steps (JSON is valid YAML)
+func Load(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read actions: %w", err)
+	}
+	var s Script
+	if err := yaml.Unmarshal(data, &s.Steps); err != nil {
+		return nil, fmt.Errorf("failed to parse actions %s: %w", path, err)
+	}
+	if len(s.Steps) == 0 {
+		return nil, fmt.Errorf("invalid actions %s: no steps defined", path)
+	}
+	for i := range s.Steps {
+		if err := s.Steps[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid actions %s: step %d: %w", path, i+1, err)
+		}
+	}
+	sum := sha256.Sum256(data)
+	s.hash = hex.EncodeToString(sum[:])
+	return &s, nil
+}
+
+// Hash identifies the script contents
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// String describes the step for progress and error messages
+func (st Step) String() string {
+	switch {
+	case st.Selector != "":
+		return fmt.Sprintf("%s %q", st.Action, st.Selector)
+	case st.Key != "":
+		return fmt.Sprintf("%s %q", st.Action, st.Key)
+	case st.For != "":
+		return fmt.Sprintf("%s %s", st.Action, st.For)
+	case st.To != "":
+		return fmt.Sprintf("%s %s", st.Action, st.To)
+	}
+	return st.Action
+}
+
+func (st *Step) validate() error {
+	st.Action = strings.ToLower(strings.TrimSpace(st.Action))
+	switch st.Action {
+	case Click:
+		if st.Selector == "" {
+			return fmt.Errorf("click requires selector")
+		}
+	case Type:
+		if st.Selector == "" {
+			return fmt.Errorf("type requires selector")
+		}
+	case Press:
+		if st.Key == "" {
+			return fmt.Errorf("press requires key")
+		}
+		if !ValidKey(st.Key) {
+			return fmt.Errorf("unknown key %q", st.Key)
+		}
+	case Select:
+		if st.Selector == "" || st.Value == "" {
+			return fmt.Errorf("select requires selector and value")
+		}
+	case Scroll:
+		switch st.To {
+		case "", "top", "bottom":
+		default:
+			return fmt.Errorf("scroll to must be top or bottom, got %q", st.To)
+		}
+		if st.Repeat < 0 || st.MS < 0 {
+			return fmt.Errorf("scroll repeat and ms must not be negative")
+		}
+	case Wait:
+		if st.For == "" && st.MS <= 0 {
+			return fmt.Errorf("wait requires for or ms")
+		}
+		if st.For != "" && st.MS > 0 {
+			return fmt.Errorf("wait takes either for or ms")
+		}
+	case Eval:
+		if st.Script == "" {
+			return fmt.Errorf("eval requires script")
+		}
+	case Screenshot:
+		switch strings.ToLower(filepath.Ext(st.Path)) {
+		case ".png", ".jpg", ".jpeg":
+		default:
+			return fmt.Errorf("screenshot requires a .png or .jpg path")
+		}
+	case "":
+		return fmt.Errorf("action is required")
+	default:
+		return fmt.Errorf("unknown action %q (click, type, press, select, scroll, wait, eval, screenshot)", st.Action)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package actions
+
+import (
+	"strings"
+
+	"github.com/go-rod/rod/lib/input"
+)
+
+// namedKeys maps press key names (case-insensitive) to keyboard keys
+var namedKeys = map[string]input.Key{
+	"enter":      input.Enter,
+	"tab":        input.Tab,
+	"escape":     input.Escape,
+	"esc":        input.Escape,
+	"backspace":  input.Backspace,
+	"delete":     input.Delete,
+	"space":      input.Space,
+	"arrowup":    input.ArrowUp,
+	"arrowdown":  input.ArrowDown,
+	"arrowleft":  input.ArrowLeft,
+	"arrowright": input.ArrowRight,
+	"pageup":     input.PageUp,
+	"pagedown":   input.PageDown,
+	"home":       input.Home,
+	"end":        input.End,
+}
+
+// KeyFor returns the keyboard key for a press key name or a single
+// printable ASCII character
+func KeyFor(name string) (input.Key, bool) {
+	if k, ok := namedKeys[strings.ToLower(name)]; ok {
+		return k, true
+	}
+	if len(name) == 1 && name[0] >= ' ' && name[0] <= '~' {
+		return input.Key(name[0]), true
+	}
+	return 0, false
+}
+
+// ValidKey reports whether name is a known press key
+func ValidKey(name string) bool {
+	_, ok := KeyFor(name)
+	return ok
+}
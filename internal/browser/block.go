@@ -0,0 +1,150 @@
+package browser
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// blocklist is the bundled ad and tracker domain list used by --block ads
+//
+//go:embed blocklist.txt
+var blocklist string
+
+// blockTypes maps --block resource type names to CDP resource types
+var blockTypes = map[string]proto.NetworkResourceType{
+	"image":      proto.NetworkResourceTypeImage,
+	"font":       proto.NetworkResourceTypeFont,
+	"media":      proto.NetworkResourceTypeMedia,
+	"stylesheet": proto.NetworkResourceTypeStylesheet,
+	"script":     proto.NetworkResourceTypeScript,
+	"xhr":        proto.NetworkResourceTypeXHR,
+	"fetch":      proto.NetworkResourceTypeFetch,
+	"websocket":  proto.NetworkResourceTypeWebSocket,
+	"manifest":   proto.NetworkResourceTypeManifest,
+	"ping":       proto.NetworkResourceTypePing,
+	"other":      proto.NetworkResourceTypeOther,
+}
+
+// BlockRules selects requests that are failed before they are sent
+type BlockRules struct {
+	Spec []string // the --block values the rules were parsed from
+
+	types    map[proto.NetworkResourceType]bool
+	patterns []*regexp.Regexp
+	domains  map[string]bool // blocked domains and their subdomains; nil when none were given
+}
+
+// ParseBlockRules parses --block values: resource type names (image, font,
+// media, stylesheet, ...), "ads" for the bundled ad/tracker domain list, bare
+// domains that also block their subdomains, or URL globs where * matches any
+// run of characters. Globs with a scheme or a leading * match the whole URL;
+// others match anywhere in it. It returns nil for no values.
+func ParseBlockRules(values []string) (*BlockRules, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	r := &BlockRules{Spec: values, types: map[proto.NetworkResourceType]bool{}}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if t, ok := blockTypes[strings.ToLower(v)]; ok {
+			r.types[t] = true
+			continue
+		}
+		switch {
+		case strings.EqualFold(v, "ads"):
+			r.addDomains(loadBlocklist())
+		case strings.Contains(v, ".") && !strings.ContainsAny(v, "*/:"):
+			r.addDomains(map[string]bool{strings.ToLower(v): true})
+		case strings.Contains(v, "://") || strings.HasPrefix(v, "*"):
+			r.patterns = append(r.patterns, globRegexp(v))
+		case strings.ContainsAny(v, "*./:"):
+			r.patterns = append(r.patterns, globRegexp("*"+v+"*"))
+		default:
+			return nil, fmt.Errorf("invalid --block value %q (a resource type, \"ads\" or a URL pattern)", v)
+		}
+	}
+	return r, nil
+}
+
+// addDomains adds blocked domains to the rules
+func (r *BlockRules) addDomains(domains map[string]bool) {
+	if r.domains == nil {
+		r.domains = map[string]bool{}
+	}
+	for d := range domains {
+		r.domains[d] = true
+	}
+}
+
+// Match reports whether a request of the given type and URL is blocked
+func (r *BlockRules) Match(resourceType proto.NetworkResourceType, rawURL string) bool {
+	if r == nil {
+		return false
+	}
+	if r.types[resourceType] {
+		return true
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(rawURL) {
+			return true
+		}
+	}
+	if r.domains != nil {
+		if u, err := url.Parse(rawURL); err == nil {
+			// Check the host and each parent domain
+			host := strings.ToLower(u.Hostname())
+			for host != "" {
+				if r.domains[host] {
+					return true
+				}
+				_, parent, ok := strings.Cut(host, ".")
+				if !ok {
+					break
+				}
+				host = parent
+			}
+		}
+	}
+	return false
+}
+
+// handler fails matching requests as blocked by the client
+func (r *BlockRules) handler() Handler {
+	return func(h *rod.Hijack) bool {
+		if !r.Match(h.Request.Type(), h.Request.URL().String()) {
+			return false
+		}
+		h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+		return true
+	}
+}
+
+// globRegexp compiles a URL glob into an anchored regexp
+func globRegexp(glob string) *regexp.Regexp {
+	parts := strings.Split(glob, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// loadBlocklist parses the bundled domain list, one domain per line
+func loadBlocklist() map[string]bool {
+	domains := map[string]bool{}
+	sc := bufio.NewScanner(strings.NewReader(blocklist))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains[strings.ToLower(line)] = true
+	}
+	return domains
+}
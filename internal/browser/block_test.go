@@ -0,0 +1,67 @@
+package browser
+
+import (
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+func TestBlockRulesMatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         []string
+		resourceType proto.NetworkResourceType
+		url          string
+		want         bool
+	}{
+		{"type matches", []string{"image"}, proto.NetworkResourceTypeImage, "https://example.com/a.png", true},
+		{"type is case-insensitive", []string{"Font"}, proto.NetworkResourceTypeFont, "https://example.com/a.woff", true},
+		{"other types pass", []string{"image"}, proto.NetworkResourceTypeScript, "https://example.com/a.js", false},
+		{"bare domain", []string{"doubleclick.net"}, proto.NetworkResourceTypeScript, "https://doubleclick.net/tag.js", true},
+		{"bare domain covers subdomains", []string{"doubleclick.net"}, proto.NetworkResourceTypeScript, "https://ad.g.doubleclick.net/tag.js", true},
+		{"bare domain needs a dot boundary", []string{"doubleclick.net"}, proto.NetworkResourceTypeScript, "https://notdoubleclick.net/tag.js", false},
+		{"bare domain ignores the path", []string{"doubleclick.net"}, proto.NetworkResourceTypeDocument, "https://example.com/doubleclick.net", false},
+		{"path matches anywhere", []string{"/ads/"}, proto.NetworkResourceTypeImage, "https://example.com/ads/banner.png", true},
+		{"path does not match elsewhere", []string{"/ads/"}, proto.NetworkResourceTypeImage, "https://example.com/adsense/banner.png", false},
+		{"host and path glob", []string{"example.com/video/*"}, proto.NetworkResourceTypeMedia, "https://cdn.example.com/video/a.mp4", true},
+		{"scheme glob matches the whole URL", []string{"*://cdn.example.com/*"}, proto.NetworkResourceTypeImage, "https://cdn.example.com/a.png", true},
+		{"scheme glob is anchored", []string{"https://cdn.example.com/*"}, proto.NetworkResourceTypeImage, "https://other.com/?u=https://cdn.example.com/a.png", false},
+		{"leading star glob", []string{"*.mp4"}, proto.NetworkResourceTypeMedia, "https://example.com/a.mp4", true},
+		{"leading star glob is anchored at the end", []string{"*.mp4"}, proto.NetworkResourceTypeMedia, "https://example.com/a.mp4?x=1", false},
+		{"ads keeps bare domains", []string{"example.org", "ads"}, proto.NetworkResourceTypeDocument, "https://www.example.org/", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseBlockRules(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Match(tt.resourceType, tt.url); got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.resourceType, tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBlockRulesErrors(t *testing.T) {
+	tests := []struct {
+		spec    []string
+		wantNil bool
+		wantErr bool
+	}{
+		{spec: nil, wantNil: true},
+		{spec: []string{"images"}, wantErr: true},
+		{spec: []string{"localhost"}, wantErr: true},
+		{spec: []string{"ads"}},
+	}
+	for _, tt := range tests {
+		r, err := ParseBlockRules(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBlockRules(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (r == nil) != tt.wantNil {
+			t.Errorf("ParseBlockRules(%q) = %v, wantNil %v", tt.spec, r, tt.wantNil)
+		}
+	}
+}
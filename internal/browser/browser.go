@@ -1,6 +1,12 @@
 package browser
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"durl/internal/har"
+
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
@@ -10,48 +16,148 @@ const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/
 
 // Browser wraps a rod.Browser instance
 type Browser struct {
-	browser  *rod.Browser
-	launcher *launcher.Launcher
-	proxyURL string
+	browser   *rod.Browser
+	launcher  *launcher.Launcher
+	proxyURL  string
+	cookieJar string        // cookies are saved here on Close when set
+	viewport  Viewport      // applied to every new page when set
+	har       *har.Recorder // every new page is recorded here when set
+	block     *BlockRules   // requests failed before they are sent; nil blocks nothing
+	mock      *MockRules    // requests answered, aborted or rewritten; nil mocks nothing
+	shared    bool          // default context of a pooled process; Close must not shut it down
+
+	mu           sync.Mutex
+	interceptors map[proto.TargetTargetID]*Interceptor // per page, stopped on Close
 }
 
 // Config holds browser configuration
 type Config struct {
-	ProxyURL string // empty string means no proxy
-	Headless bool   // true = headless (default), false = headed
+	ProxyURL    string                      // empty string means no proxy
+	Headless    bool                        // true = headless (default), false = headed
+	UserDataDir string                      // persistent profile directory; empty means a throwaway profile
+	Cookies     []*proto.NetworkCookieParam // cookies preloaded into the browser context
+	CookieJar   string                      // file the context's cookies are saved to on Close
+	Viewport    Viewport                    // page viewport; zero keeps the default
+	HAR         *har.Recorder               // records the traffic of every page when set
+	Block       *BlockRules                 // --block: resource types, URL patterns and ad/tracker domains
+	Mock        *MockRules                  // --mock: fixed responses, aborts and header rewrites
+}
+
+// Viewport is the window size and device scale factor pages render with
+type Viewport struct {
+	Width  int
+	Height int
+	Scale  float64 // device scale factor; 0 means 1
 }
 
 // New creates a browser instance
 func New(cfg Config) (*Browser, error) {
-	return newBrowser(cfg.ProxyURL, cfg.Headless)
+	b, err := newBrowser(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.setup(cfg); err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	return b, nil
 }
 
-func newBrowser(proxyURL string, headless bool) (*Browser, error) {
-	l := launcher.New().Headless(headless)
+// newBrowser launches and connects to a browser process
+func newBrowser(cfg Config) (*Browser, error) {
+	l := launcher.New().Headless(cfg.Headless)
 
-	if proxyURL != "" {
-		l = l.Proxy(proxyURL)
+	if cfg.ProxyURL != "" {
+		l = l.Proxy(cfg.ProxyURL)
+	}
+	if cfg.UserDataDir != "" {
+		l = l.UserDataDir(cfg.UserDataDir)
 	}
 
-	url := l.MustLaunch()
-	browser := rod.New().ControlURL(url).MustConnect()
+	url, err := l.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	browser := rod.New().ControlURL(url)
+	if err := browser.Connect(); err != nil {
+		l.Kill()
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
 
 	b := &Browser{
 		browser:  browser,
 		launcher: l,
-		proxyURL: proxyURL,
+		proxyURL: cfg.ProxyURL,
 	}
 
 	return b, nil
 }
 
+// setup applies the per-context parts of cfg: preloaded cookies and the cookie jar
+func (b *Browser) setup(cfg Config) error {
+	if len(cfg.Cookies) > 0 {
+		if err := b.browser.SetCookies(cfg.Cookies); err != nil {
+			return fmt.Errorf("failed to load cookies: %w", err)
+		}
+	}
+	b.cookieJar = cfg.CookieJar
+	b.viewport = cfg.Viewport
+	b.har = cfg.HAR
+	b.block = cfg.Block
+	b.mock = cfg.Mock
+	return nil
+}
+
+// HasCookie reports whether the browser context holds a cookie with the given
+// name that is sent to host: one set for host itself or a parent domain
+func (b *Browser) HasCookie(host, name string) bool {
+	cookies, err := b.browser.GetCookies()
+	if err != nil {
+		return false
+	}
+	host = strings.ToLower(host)
+	for _, c := range cookies {
+		domain := strings.TrimPrefix(strings.ToLower(c.Domain), ".")
+		if c.Name == name && (host == domain || strings.HasSuffix(host, "."+domain)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cookies returns the cookies of the browser context
+func (b *Browser) Cookies() ([]*proto.NetworkCookie, error) {
+	return b.browser.GetCookies()
+}
+
+// SetCookies adds cookies to the browser context
+func (b *Browser) SetCookies(cookies []*proto.NetworkCookieParam) error {
+	if len(cookies) == 0 {
+		// rod clears every cookie when given none
+		return nil
+	}
+	return b.browser.SetCookies(cookies)
+}
+
 // GetProxyURL returns the proxy URL in use
 func (b *Browser) GetProxyURL() string {
 	return b.proxyURL
 }
 
 // NewPage creates a new browser page with anti-detection measures applied.
+// Pages share the context's cookies, so preloaded cookies apply to every page.
 func (b *Browser) NewPage() (*rod.Page, error) {
+	return b.newPage(true)
+}
+
+// NewUnrecordedPage creates a page like NewPage that is kept out of the HAR,
+// for pages that submit credentials
+func (b *Browser) NewUnrecordedPage() (*rod.Page, error) {
+	return b.newPage(false)
+}
+
+// newPage creates a page, recording it in the HAR when record is set
+func (b *Browser) newPage(record bool) (*rod.Page, error) {
 	page, err := b.browser.Page(proto.TargetCreateTarget{})
 	if err != nil {
 		return nil, err
@@ -60,11 +166,57 @@ func (b *Browser) NewPage() (*rod.Page, error) {
 		UserAgent: defaultUserAgent,
 	})
 	_, _ = page.EvalOnNewDocument(`Object.defineProperty(navigator, 'webdriver', {get: () => undefined});`)
+	if b.viewport.Width > 0 {
+		scale := b.viewport.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		if err := page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             b.viewport.Width,
+			Height:            b.viewport.Height,
+			DeviceScaleFactor: scale,
+		}); err != nil {
+			_ = page.Close()
+			return nil, fmt.Errorf("failed to set viewport: %w", err)
+		}
+	}
+	if record {
+		b.har.Attach(page)
+	}
+	if b.mock != nil || b.block != nil {
+		ic, err := b.Intercept(page)
+		if err != nil {
+			_ = page.Close()
+			return nil, err
+		}
+		// Mock rules come first so a mocked URL is answered even if blocked
+		if b.mock != nil {
+			ic.Use(b.mock.handler(page, b.proxyURL))
+		}
+		if b.block != nil {
+			ic.Use(b.block.handler())
+		}
+	}
 	return page, nil
 }
 
-// Close shuts down the browser and cleans up resources
+// Close saves cookies to the jar (if configured), then shuts down the browser
+// and cleans up resources
 func (b *Browser) Close() error {
+	b.mu.Lock()
+	for _, ic := range b.interceptors {
+		ic.stop()
+	}
+	b.interceptors = nil
+	b.mu.Unlock()
+
+	var jarErr error
+	if b.browser != nil && b.cookieJar != "" {
+		jarErr = b.saveCookies()
+	}
+	if b.shared {
+		return jarErr
+	}
 	if b.browser != nil {
 		if err := b.browser.Close(); err != nil {
 			return err
@@ -73,5 +225,14 @@ func (b *Browser) Close() error {
 	if b.launcher != nil {
 		b.launcher.Kill()
 	}
-	return nil
+	return jarErr
+}
+
+// saveCookies writes the context's current cookies to the cookie jar
+func (b *Browser) saveCookies() error {
+	cookies, err := b.browser.GetCookies()
+	if err != nil {
+		return fmt.Errorf("failed to read cookies: %w", err)
+	}
+	return SaveCookies(b.cookieJar, cookies)
 }
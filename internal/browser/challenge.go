@@ -0,0 +1,36 @@
+package browser
+
+import (
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// DetectChallenge inspects the rendered page for captcha or anti-bot
+// challenge markers and returns a short description, or "" if none are found.
+func DetectChallenge(page *rod.Page) string {
+	result, err := page.Timeout(5 * time.Second).Eval(`() => {
+		const markers = [
+			['#challenge-form, #cf-challenge-running, .cf-turnstile', 'cloudflare challenge'],
+			['.g-recaptcha, iframe[src*="recaptcha"]', 'recaptcha'],
+			['.h-captcha, iframe[src*="hcaptcha"]', 'hcaptcha'],
+			['#nc_1_wrapper, .nc-container', 'aliyun slider captcha'],
+			['.passMod_dialog-wrapper, #verify-container', 'verification dialog'],
+		];
+		for (const [sel, name] of markers) {
+			if (document.querySelector(sel)) return name;
+		}
+		// Titles rendered by common block pages, including Chinese portals
+		// ("安全验证" = security check, "验证码" = captcha, "人机验证" = human verification)
+		const title = (document.title || '').toLowerCase();
+		const titles = ['captcha', 'attention required', 'verify you are human', 'access denied', 'just a moment', '安全验证', '验证码', '人机验证'];
+		for (const t of titles) {
+			if (title.includes(t)) return 'challenge page: ' + document.title;
+		}
+		return '';
+	}`)
+	if err != nil {
+		return ""
+	}
+	return result.Value.Str()
+}
@@ -0,0 +1,274 @@
+package browser
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// jarMu serializes cookie jar writes from concurrently closing browsers
+var jarMu sync.Mutex
+
+// jsonCookie is the on-disk JSON cookie format, compatible with the arrays
+// exported by Puppeteer/Playwright and browser extensions such as EditThisCookie
+type jsonCookie struct {
+	Name           string  `json:"name"`
+	Value          string  `json:"value"`
+	Domain         string  `json:"domain"`
+	Path           string  `json:"path"`
+	Expires        float64 `json:"expires,omitempty"`
+	ExpirationDate float64 `json:"expirationDate,omitempty"` // EditThisCookie export
+	HTTPOnly       bool    `json:"httpOnly,omitempty"`
+	Secure         bool    `json:"secure,omitempty"`
+	SameSite       string  `json:"sameSite,omitempty"`
+}
+
+// LoadCookies reads a Netscape (curl/wget) or JSON cookie file.
+// Expired cookies are dropped; a missing file yields no cookies.
+func LoadCookies(path string) ([]*proto.NetworkCookieParam, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
+	}
+
+	var cookies []*proto.NetworkCookieParam
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		cookies, err = parseJSONCookies(trimmed)
+	} else {
+		cookies, err = parseNetscapeCookies(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cookie file %s: %w", path, err)
+	}
+
+	now := float64(time.Now().Unix())
+	live := cookies[:0]
+	for _, c := range cookies {
+		if c.Expires == 0 || float64(c.Expires) > now {
+			live = append(live, c)
+		}
+	}
+	return live, nil
+}
+
+// SaveCookies merges cookies into the jar at path, replacing entries with the
+// same name, domain and path. Files ending in .json are written as JSON,
+// everything else in Netscape format.
+func SaveCookies(path string, cookies []*proto.NetworkCookie) error {
+	jarMu.Lock()
+	defer jarMu.Unlock()
+
+	existing, err := LoadCookies(path)
+	if err != nil {
+		return err
+	}
+
+	type cookieKey struct{ name, domain, path string }
+	merged := make([]*proto.NetworkCookieParam, 0, len(existing)+len(cookies))
+	index := make(map[cookieKey]int)
+	add := func(c *proto.NetworkCookieParam) {
+		k := cookieKey{c.Name, c.Domain, c.Path}
+		if i, ok := index[k]; ok {
+			merged[i] = c
+			return
+		}
+		index[k] = len(merged)
+		merged = append(merged, c)
+	}
+	for _, c := range existing {
+		add(c)
+	}
+	for _, c := range cookies {
+		expires := c.Expires
+		if c.Session {
+			expires = 0
+		}
+		add(&proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: c.SameSite,
+			Expires:  expires,
+		})
+	}
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = formatJSONCookies(merged)
+		if err != nil {
+			return fmt.Errorf("failed to encode cookies: %w", err)
+		}
+	} else {
+		data = formatNetscapeCookies(merged)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// parseJSONCookies parses a JSON array of cookies (or an object with a "cookies" array)
+func parseJSONCookies(data []byte) ([]*proto.NetworkCookieParam, error) {
+	var list []jsonCookie
+	if data[0] == '{' {
+		var wrapped struct {
+			Cookies []jsonCookie `json:"cookies"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, err
+		}
+		list = wrapped.Cookies
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+
+	cookies := make([]*proto.NetworkCookieParam, 0, len(list))
+	for _, c := range list {
+		expires := c.Expires
+		if expires == 0 {
+			expires = c.ExpirationDate
+		}
+		if expires < 0 {
+			expires = 0
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		cookies = append(cookies, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			SameSite: normalizeSameSite(c.SameSite),
+			Expires:  proto.TimeSinceEpoch(expires),
+		})
+	}
+	return cookies, nil
+}
+
+// parseNetscapeCookies parses the tab-separated cookie file format used by curl:
+// domain, include-subdomains, path, secure, expires, name, value
+func parseNetscapeCookies(data []byte) ([]*proto.NetworkCookieParam, error) {
+	var cookies []*proto.NetworkCookieParam
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := false
+		if strings.HasPrefix(line, "#HttpOnly_") {
+			line = strings.TrimPrefix(line, "#HttpOnly_")
+			httpOnly = true
+		}
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("line %d: expected 7 tab-separated fields, got %d", lineNum, len(fields))
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry %q", lineNum, fields[4])
+		}
+
+		domain := fields[0]
+		if strings.EqualFold(fields[1], "TRUE") && !strings.HasPrefix(domain, ".") {
+			domain = "." + domain
+		}
+		cookies = append(cookies, &proto.NetworkCookieParam{
+			Domain:   domain,
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			Expires:  proto.TimeSinceEpoch(expires),
+			Name:     fields[5],
+			Value:    fields[6],
+			HTTPOnly: httpOnly,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+// formatNetscapeCookies writes cookies in curl's cookie jar format
+func formatNetscapeCookies(cookies []*proto.NetworkCookieParam) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Netscape HTTP Cookie File\n")
+	buf.WriteString("# This file was generated by durl. Edit at your own risk.\n\n")
+	for _, c := range cookies {
+		if c.HTTPOnly {
+			buf.WriteString("#HttpOnly_")
+		}
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			c.Domain,
+			boolField(strings.HasPrefix(c.Domain, ".")),
+			c.Path,
+			boolField(c.Secure),
+			int64(c.Expires),
+			c.Name,
+			c.Value,
+		)
+	}
+	return buf.Bytes()
+}
+
+// formatJSONCookies writes cookies as a JSON array
+func formatJSONCookies(cookies []*proto.NetworkCookieParam) ([]byte, error) {
+	list := make([]jsonCookie, 0, len(cookies))
+	for _, c := range cookies {
+		list = append(list, jsonCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Expires:  float64(c.Expires),
+			HTTPOnly: c.HTTPOnly,
+			Secure:   c.Secure,
+			SameSite: string(c.SameSite),
+		})
+	}
+	return json.MarshalIndent(list, "", "  ")
+}
+
+// normalizeSameSite maps exported sameSite spellings ("lax", "no_restriction") to CDP values
+func normalizeSameSite(s string) proto.NetworkCookieSameSite {
+	switch strings.ToLower(s) {
+	case "strict":
+		return proto.NetworkCookieSameSiteStrict
+	case "lax":
+		return proto.NetworkCookieSameSiteLax
+	case "none", "no_restriction":
+		return proto.NetworkCookieSameSiteNone
+	default:
+		return ""
+	}
+}
+
+func boolField(b bool) string {
+	if b {
+		return "TRUE"
+	}
+	return "FALSE"
+}
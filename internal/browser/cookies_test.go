@@ -0,0 +1,135 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// future is an expiry in 2100, so the cookies are not dropped as expired
+const future = proto.TimeSinceEpoch(4102444800)
+
+func TestSaveLoadCookiesRoundTrip(t *testing.T) {
+	saved := []*proto.NetworkCookie{
+		{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: future, HTTPOnly: true, Secure: true, SameSite: proto.NetworkCookieSameSiteLax},
+		{Name: "pref", Value: "a=b c", Domain: "www.example.com", Path: "/app", Expires: future},
+		{Name: "tmp", Value: "1", Domain: "example.com", Path: "/", Expires: future, Session: true},
+	}
+	tests := []struct {
+		file     string
+		sameSite proto.NetworkCookieSameSite // Netscape files have no sameSite column
+	}{
+		{"jar.txt", ""},
+		{"jar.json", proto.NetworkCookieSameSiteLax},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := SaveCookies(path, saved); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadCookies(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []*proto.NetworkCookieParam{
+				{Name: "sid", Value: "abc", Domain: ".example.com", Path: "/", Expires: future, HTTPOnly: true, Secure: true, SameSite: tt.sameSite},
+				{Name: "pref", Value: "a=b c", Domain: "www.example.com", Path: "/app", Expires: future},
+				{Name: "tmp", Value: "1", Domain: "example.com", Path: "/"},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadCookies() = %+v, want %+v", dumpCookies(got), dumpCookies(want))
+			}
+		})
+	}
+}
+
+func TestSaveCookiesMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jar.txt")
+	if err := SaveCookies(path, []*proto.NetworkCookie{
+		{Name: "a", Value: "1", Domain: "example.com", Path: "/"},
+		{Name: "b", Value: "1", Domain: "example.com", Path: "/"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCookies(path, []*proto.NetworkCookie{{Name: "a", Value: "2", Domain: "example.com", Path: "/"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadCookies(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Value != "2" || got[1].Name != "b" {
+		t.Errorf("LoadCookies() = %+v, want a=2 replaced in place and b kept", dumpCookies(got))
+	}
+}
+
+func TestLoadCookiesFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []*proto.NetworkCookieParam
+	}{
+		{
+			name: "netscape include-subdomains adds a dot",
+			data: "# comment\nexample.com\tTRUE\t/\tFALSE\t0\tk\tv\n",
+			want: []*proto.NetworkCookieParam{{Name: "k", Value: "v", Domain: ".example.com", Path: "/"}},
+		},
+		{
+			name: "netscape expired cookies are dropped",
+			data: "example.com\tFALSE\t/\tFALSE\t1\told\tv\nexample.com\tFALSE\t/\tTRUE\t4102444800\tnew\tv\n",
+			want: []*proto.NetworkCookieParam{{Name: "new", Value: "v", Domain: "example.com", Path: "/", Secure: true, Expires: future}},
+		},
+		{
+			name: "EditThisCookie export",
+			data: `[{"name":"k","value":"v","domain":".example.com","expirationDate":4102444800,"sameSite":"no_restriction"}]`,
+			want: []*proto.NetworkCookieParam{{Name: "k", Value: "v", Domain: ".example.com", Path: "/", Expires: future, SameSite: proto.NetworkCookieSameSiteNone}},
+		},
+		{
+			name: "Playwright storage state",
+			data: `{"cookies":[{"name":"k","value":"v","domain":"example.com","path":"/x","expires":-1,"sameSite":"Strict"}]}`,
+			want: []*proto.NetworkCookieParam{{Name: "k", Value: "v", Domain: "example.com", Path: "/x", SameSite: proto.NetworkCookieSameSiteStrict}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cookies")
+			if err := os.WriteFile(path, []byte(tt.data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadCookies(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadCookies() = %+v, want %+v", dumpCookies(got), dumpCookies(tt.want))
+			}
+		})
+	}
+}
+
+func TestLoadCookiesErrors(t *testing.T) {
+	got, err := LoadCookies(filepath.Join(t.TempDir(), "missing.txt"))
+	if err != nil || got != nil {
+		t.Errorf("missing file: got %v, %v; want no cookies and no error", got, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(path, []byte("example.com\tFALSE\t/\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCookies(path); err == nil {
+		t.Error("short Netscape line: want an error")
+	}
+}
+
+func dumpCookies(cookies []*proto.NetworkCookieParam) []proto.NetworkCookieParam {
+	out := make([]proto.NetworkCookieParam, len(cookies))
+	for i, c := range cookies {
+		out[i] = *c
+	}
+	return out
+}
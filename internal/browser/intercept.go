@@ -0,0 +1,144 @@
+package browser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Handler inspects a paused request and returns true if it answered it
+// (continued, fulfilled or failed); otherwise the next handler is asked
+type Handler func(h *rod.Hijack) bool
+
+// Rewriter changes a paused request before any handler sees it by editing
+// h.Request.Req() (method, headers, body) and returns true if it did. The
+// first rewriter that applies wins; the rewritten request is what handlers
+// match against and what is sent when no handler answers.
+type Rewriter func(h *rod.Hijack) bool
+
+// Interceptor is the single request-interception router of a page. Fetch
+// interception is configured per page, so blocking rules, request rewriting
+// and other handlers share one router and are consulted in the order they
+// were added, after any rewriters. Requests no handler answers are continued
+// as rewritten, or unchanged.
+type Interceptor struct {
+	router *rod.HijackRouter
+
+	mu        sync.RWMutex
+	rewriters []*Rewriter
+	handlers  []*Handler
+}
+
+// Intercept returns the page's interceptor, enabling interception on first use
+func (b *Browser) Intercept(page *rod.Page) (*Interceptor, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ic, ok := b.interceptors[page.TargetID]; ok {
+		return ic, nil
+	}
+	ic := &Interceptor{router: page.HijackRequests()}
+	if err := ic.router.Add("*", "", ic.handle); err != nil {
+		return nil, fmt.Errorf("failed to enable request interception: %w", err)
+	}
+	go ic.router.Run()
+
+	if b.interceptors == nil {
+		b.interceptors = make(map[proto.TargetTargetID]*Interceptor)
+	}
+	b.interceptors[page.TargetID] = ic
+	return ic, nil
+}
+
+// Use appends a handler; the returned function removes it again
+func (ic *Interceptor) Use(fn Handler) (remove func()) {
+	h := &fn
+	ic.mu.Lock()
+	ic.handlers = append(ic.handlers, h)
+	ic.mu.Unlock()
+
+	return func() {
+		ic.mu.Lock()
+		defer ic.mu.Unlock()
+		for i, existing := range ic.handlers {
+			if existing == h {
+				ic.handlers = append(ic.handlers[:i:i], ic.handlers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Rewrite adds a rewriter and returns a function that removes it again
+func (ic *Interceptor) Rewrite(fn Rewriter) (remove func()) {
+	rw := &fn
+	ic.mu.Lock()
+	ic.rewriters = append(ic.rewriters, rw)
+	ic.mu.Unlock()
+
+	return func() {
+		ic.mu.Lock()
+		defer ic.mu.Unlock()
+		for i, existing := range ic.rewriters {
+			if existing == rw {
+				ic.rewriters = append(ic.rewriters[:i:i], ic.rewriters[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (ic *Interceptor) handle(h *rod.Hijack) {
+	ic.mu.RLock()
+	rewriters := append([]*Rewriter{}, ic.rewriters...)
+	handlers := append([]*Handler{}, ic.handlers...)
+	ic.mu.RUnlock()
+
+	rewritten := false
+	for _, fn := range rewriters {
+		if (*fn)(h) {
+			rewritten = true
+			break
+		}
+	}
+	for _, fn := range handlers {
+		if (*fn)(h) {
+			return
+		}
+	}
+	if rewritten {
+		ContinueWith(h, h.Request.Req().Header)
+		return
+	}
+	h.ContinueRequest(&proto.FetchContinueRequest{})
+}
+
+// ContinueWith continues h with the method and body of h.Request.Req(),
+// which carry any rewrite, and the given headers
+func ContinueWith(h *rod.Hijack, headers map[string][]string) {
+	req := h.Request.Req()
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		// Leave the body readable for a later LoadResponse
+		h.Request.SetBody(body)
+	}
+	entries := make([]*proto.FetchHeaderEntry, 0, len(headers))
+	for k, v := range headers {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: k, Value: strings.Join(v, ", ")})
+	}
+	h.ContinueRequest(&proto.FetchContinueRequest{
+		Method:   req.Method,
+		PostData: body,
+		Headers:  entries,
+	})
+}
+
+// stop disables interception
+func (ic *Interceptor) stop() {
+	_ = ic.router.Stop()
+}
@@ -0,0 +1,273 @@
+package browser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// MockRules substitute, abort or rewrite matching requests (--mock). The
+// first rule that matches a request applies.
+type MockRules struct {
+	Rules []MockRule `yaml:"rules"`
+
+	hash string // content hash of the rules file and bodies, for cache keys
+}
+
+// MockRule is one rule of a --mock file. A rule either answers the request
+// (status/headers/body/body_file), aborts it, or lets it through with
+// request and response headers set or removed.
+type MockRule struct {
+	Match  string `yaml:"match"`  // URL glob, * matches anything
+	Method string `yaml:"method"` // optional HTTP method
+	Type   string `yaml:"type"`   // optional resource type (xhr, fetch, script, ...)
+
+	Status   int               `yaml:"status"`    // mocked status, default 200
+	Headers  map[string]string `yaml:"headers"`   // mocked response headers
+	Body     string            `yaml:"body"`      // mocked body
+	BodyFile string            `yaml:"body_file"` // mocked body from a file, relative to the rules file
+
+	Abort bool `yaml:"abort"` // fail the request as blocked by the client
+
+	SetRequestHeaders     map[string]string `yaml:"set_request_headers"`
+	RemoveRequestHeaders  []string          `yaml:"remove_request_headers"`
+	SetResponseHeaders    map[string]string `yaml:"set_response_headers"`
+	RemoveResponseHeaders []string          `yaml:"remove_response_headers"`
+
+	re   *regexp.Regexp
+	kind string // mockRespond, mockAbort or mockRewrite
+	body []byte
+}
+
+// What a mock rule does with a matching request
+const (
+	mockRespond = "respond"
+	mockAbort   = "abort"
+	mockRewrite = "rewrite"
+)
+
+// LoadMockRules reads a YAML (or JSON) rules file
+func LoadMockRules(path string) (*MockRules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock rules: %w", err)
+	}
+	var m MockRules
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse mock rules %s: %w", path, err)
+	}
+	if len(m.Rules) == 0 {
+		return nil, fmt.Errorf("invalid mock rules %s: no rules defined", path)
+	}
+
+	sum := sha256.New()
+	sum.Write(data)
+	dir := filepath.Dir(path)
+	for i := range m.Rules {
+		r := &m.Rules[i]
+		if err := r.compile(dir); err != nil {
+			return nil, fmt.Errorf("invalid mock rule %d in %s: %w", i+1, path, err)
+		}
+		sum.Write(r.body)
+	}
+	m.hash = hex.EncodeToString(sum.Sum(nil))
+	return &m, nil
+}
+
+// Hash identifies the rules and the bodies they serve
+func (m *MockRules) Hash() string {
+	return m.hash
+}
+
+func (r *MockRule) compile(dir string) error {
+	if r.Match == "" {
+		return fmt.Errorf("match is required")
+	}
+	r.re = globRegexp(r.Match)
+	if r.Type != "" {
+		if _, ok := blockTypes[strings.ToLower(r.Type)]; !ok && !strings.EqualFold(r.Type, "document") {
+			return fmt.Errorf("unknown resource type %q", r.Type)
+		}
+	}
+
+	respond := r.Status != 0 || r.Headers != nil || r.Body != "" || r.BodyFile != ""
+	rewrite := r.SetRequestHeaders != nil || r.RemoveRequestHeaders != nil || r.SetResponseHeaders != nil || r.RemoveResponseHeaders != nil
+	kinds := 0
+	for _, k := range []bool{respond, r.Abort, rewrite} {
+		if k {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("a rule must do exactly one of: respond (status/headers/body), abort, rewrite headers")
+	}
+	switch {
+	case respond:
+		r.kind = mockRespond
+	case r.Abort:
+		r.kind = mockAbort
+	default:
+		r.kind = mockRewrite
+	}
+	if r.Body != "" && r.BodyFile != "" {
+		return fmt.Errorf("body and body_file are mutually exclusive")
+	}
+
+	r.body = []byte(r.Body)
+	if r.BodyFile != "" {
+		path := r.BodyFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read body_file: %w", err)
+		}
+		r.body = data
+	}
+	if respond && r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a request
+func (r *MockRule) matches(method string, resourceType proto.NetworkResourceType, rawURL string) bool {
+	if r.Method != "" && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if r.Type != "" && !strings.EqualFold(r.Type, string(resourceType)) {
+		return false
+	}
+	return r.re.MatchString(rawURL)
+}
+
+// handler applies the first matching rule. Response header rewrites load
+// the response outside the browser, through proxyURL when set and with the
+// cookies the page would send.
+func (m *MockRules) handler(page *rod.Page, proxyURL string) Handler {
+	client := &http.Client{
+		// Redirects are passed back to the browser, which follows them itself
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	if u, err := url.Parse(proxyURL); err == nil && proxyURL != "" {
+		client.Transport = &http.Transport{Proxy: http.ProxyURL(u)}
+	}
+
+	return func(h *rod.Hijack) bool {
+		rawURL := h.Request.URL().String()
+		for i := range m.Rules {
+			r := &m.Rules[i]
+			// The method as rewritten by -X, not as the browser sent it
+			if !r.matches(h.Request.Req().Method, h.Request.Type(), rawURL) {
+				continue
+			}
+			switch r.kind {
+			case mockAbort:
+				h.Response.Fail(proto.NetworkErrorReasonBlockedByClient)
+			case mockRespond:
+				r.respond(h)
+			default:
+				r.rewrite(h, page, client)
+			}
+			return true
+		}
+		return false
+	}
+}
+
+// respond fulfils the request with the mocked response
+func (r *MockRule) respond(h *rod.Hijack) {
+	h.Response.Payload().ResponseCode = r.Status
+	contentType := ""
+	for k, v := range r.Headers {
+		h.Response.SetHeader(k, v)
+		if strings.EqualFold(k, "Content-Type") {
+			contentType = v
+		}
+	}
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(r.BodyFile))
+		if contentType == "" {
+			contentType = http.DetectContentType(r.body)
+		}
+		h.Response.SetHeader("Content-Type", contentType)
+	}
+	h.Response.SetBody(r.body)
+}
+
+// rewrite sends the request with its headers rewritten and, if response
+// headers are rewritten too, fulfils it with the adjusted response
+func (r *MockRule) rewrite(h *rod.Hijack, page *rod.Page, client *http.Client) {
+	headers := http.Header{}
+	for k, v := range h.Request.Req().Header {
+		headers.Set(k, strings.Join(v, ", "))
+	}
+	reload := r.SetResponseHeaders != nil || r.RemoveResponseHeaders != nil
+	if reload && headers.Get("Cookie") == "" {
+		// Paused requests do not list cookies; the browser adds them when
+		// it sends the request, so a reload outside it must add them itself
+		if cookie := pageCookies(page, h.Request.URL().String()); cookie != "" {
+			headers.Set("Cookie", cookie)
+		}
+	}
+	for k, v := range r.SetRequestHeaders {
+		headers.Set(k, v)
+	}
+	for _, k := range r.RemoveRequestHeaders {
+		headers.Del(k)
+	}
+
+	if !reload {
+		ContinueWith(h, headers)
+		return
+	}
+
+	h.Request.Req().Header = headers
+	if err := h.LoadResponse(client, true); err != nil {
+		h.Response.Fail(proto.NetworkErrorReasonConnectionFailed)
+		return
+	}
+	payload := h.Response.Payload()
+	kept := payload.ResponseHeaders[:0]
+	for _, e := range payload.ResponseHeaders {
+		drop := false
+		for _, k := range r.RemoveResponseHeaders {
+			drop = drop || strings.EqualFold(e.Name, k)
+		}
+		for k := range r.SetResponseHeaders {
+			drop = drop || strings.EqualFold(e.Name, k)
+		}
+		if !drop {
+			kept = append(kept, e)
+		}
+	}
+	payload.ResponseHeaders = kept
+	for k, v := range r.SetResponseHeaders {
+		h.Response.SetHeader(k, v)
+	}
+}
+
+// pageCookies returns the Cookie header value the page would send to rawURL
+func pageCookies(page *rod.Page, rawURL string) string {
+	res, err := proto.NetworkGetCookies{Urls: []string{rawURL}}.Call(page)
+	if err != nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(res.Cookies))
+	for _, c := range res.Cookies {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
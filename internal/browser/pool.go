@@ -0,0 +1,133 @@
+package browser
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// poolKey identifies a warm browser process; browsers launched with the same
+// proxy and headless settings are interchangeable.
+type poolKey struct {
+	proxyURL    string
+	headless    bool
+	userDataDir string
+}
+
+// Pool keeps warm browser processes so batch jobs do not pay a Chromium
+// launch per scrape. Each Acquire hands out an isolated incognito context on
+// a shared process; closing the returned Browser only disposes the context.
+// Processes with a persistent profile hand out their default context instead,
+// since incognito contexts would bypass the profile.
+type Pool struct {
+	mu    sync.Mutex
+	slots map[poolKey]*poolSlot
+}
+
+// poolSlot holds the process for one key; its lock serializes launches for
+// that key without blocking Acquire calls for other keys
+type poolSlot struct {
+	mu     sync.Mutex
+	b      *Browser // nil until launched
+	closed bool     // the pool was closed; nothing may launch here
+}
+
+// NewPool creates an empty browser pool
+func NewPool() *Pool {
+	return &Pool{slots: make(map[poolKey]*poolSlot)}
+}
+
+// Acquire returns an incognito browser context for cfg, launching or
+// relaunching (if the previous process crashed) the underlying browser as
+// needed. A nil Pool falls back to launching a dedicated browser, so callers
+// can always Close the result.
+func (p *Pool) Acquire(cfg Config) (*Browser, error) {
+	if p == nil {
+		return New(cfg)
+	}
+
+	b, err := p.browser(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &Browser{browser: b.browser, proxyURL: b.proxyURL, shared: true}
+	if cfg.UserDataDir == "" {
+		incognito, err := b.browser.Incognito()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create browser context: %w", err)
+		}
+		ctx = &Browser{browser: incognito, proxyURL: b.proxyURL}
+	}
+
+	if err := ctx.setup(cfg); err != nil {
+		_ = ctx.Close()
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// browser returns the live process for cfg's key, launching it if needed.
+// The pool lock is only held to find the slot, so a slow launch or health
+// check does not stall Acquire calls for other keys.
+func (p *Pool) browser(cfg Config) (*Browser, error) {
+	key := poolKey{proxyURL: cfg.ProxyURL, headless: cfg.Headless, userDataDir: cfg.UserDataDir}
+	p.mu.Lock()
+	slot, ok := p.slots[key]
+	if !ok {
+		slot = &poolSlot{}
+		p.slots[key] = slot
+	}
+	p.mu.Unlock()
+
+	slot.mu.Lock()
+	defer slot.mu.Unlock()
+	if slot.closed {
+		return nil, fmt.Errorf("browser pool is closed")
+	}
+	if slot.b != nil && !slot.b.alive() {
+		// Recycle crashed or disconnected instances
+		_ = slot.b.Close()
+		slot.b = nil
+	}
+	if slot.b == nil {
+		b, err := newBrowser(cfg)
+		if err != nil {
+			return nil, err
+		}
+		slot.b = b
+	}
+	return slot.b, nil
+}
+
+// Close shuts down all pooled browser processes
+func (p *Pool) Close() error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	slots := p.slots
+	p.slots = make(map[poolKey]*poolSlot)
+	p.mu.Unlock()
+
+	var firstErr error
+	for _, slot := range slots {
+		slot.mu.Lock()
+		if slot.b != nil {
+			if err := slot.b.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			slot.b = nil
+		}
+		slot.closed = true
+		slot.mu.Unlock()
+	}
+	return firstErr
+}
+
+// alive reports whether the browser process still answers CDP calls
+func (b *Browser) alive() bool {
+	_, err := b.browser.Timeout(5 * time.Second).Version()
+	return err == nil
+}
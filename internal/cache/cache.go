@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrMiss is returned in offline mode when no entry exists for a request
+var ErrMiss = errors.New("not in cache")
+
+// Config holds cache settings
+type Config struct {
+	Dir      string        // cache directory (default: <user cache dir>/durl)
+	TTL      time.Duration // entries younger than this are reused; <= 0 always fetches
+	Disabled bool          // --no-cache: neither read nor store entries
+	Offline  bool          // --offline: only read, ignoring age; a miss is an error
+}
+
+// Cache stores scraped content on disk, one JSON file per request key.
+// Fetches are only stored when a TTL is set, and entries are only read back
+// within the TTL or in offline mode. A nil *Cache is disabled.
+type Cache struct {
+	cfg Config
+}
+
+// Key identifies a cached request
+type Key struct {
+	Site     string            `json:"site,omitempty"` // empty for generic fetches
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`               // URL, or query for site modes
+	Headers  string            `json:"headers,omitempty"` // hash of the request headers, which may hold credentials
+	Body     string            `json:"body,omitempty"`    // hash of the request body
+	Cookies  string            `json:"cookies,omitempty"` // hash of the -b cookies preloaded into the browser
+	Profile  string            `json:"profile,omitempty"` // hash of the --user-data-dir path
+	Wait     string            `json:"wait,omitempty"`    // hash of the wait strategy and targets
+	Level    string            `json:"level,omitempty"`
+	Selector string            `json:"selector,omitempty"`
+	Extra    map[string]string `json:"extra,omitempty"`    // site-specific parameters
+	Schema   string            `json:"schema,omitempty"`   // hash of the --schema file
+	Capture  string            `json:"capture,omitempty"`  // capture format (-f png, pdf, mhtml, ...) and viewport settings
+	Block    []string          `json:"block,omitempty"`    // --block rules, which change what the page renders
+	Mock     string            `json:"mock,omitempty"`     // hash of the --mock rules and bodies
+	Actions  string            `json:"actions,omitempty"`  // hash of the --actions script
+	Paginate string            `json:"paginate,omitempty"` // --paginate mode, next selector, dedupe key and page limit
+	Login    string            `json:"login,omitempty"`    // hash of the --login file and its resolved credentials
+	Pierce   bool              `json:"pierce,omitempty"`   // --pierce shadow roots
+	Frames   bool              `json:"frames,omitempty"`   // --frames search and inlining
+	Attr     string            `json:"attr,omitempty"`     // --attr output
+	Text     bool              `json:"text,omitempty"`     // --text output
+	Table    string            `json:"table,omitempty"`    // --table selection
+}
+
+// entry is the on-disk format of one cached response
+type entry struct {
+	Key      Key             `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Content  json.RawMessage `json:"content"`
+}
+
+// New creates a Cache, defaulting Dir to the user cache directory (or the
+// temporary directory when there is none)
+func New(cfg Config) *Cache {
+	if cfg.Dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = os.TempDir()
+		}
+		cfg.Dir = filepath.Join(base, "durl")
+	}
+	return &Cache{cfg: cfg}
+}
+
+// Offline reports whether requests must be served from the cache
+func (c *Cache) Offline() bool {
+	return c != nil && !c.cfg.Disabled && c.cfg.Offline
+}
+
+// Get returns the cached content snapshot for k and when it was stored.
+// ok is false when the entry is missing or expired, or reads are disabled.
+func (c *Cache) Get(k Key) (data []byte, storedAt time.Time, ok bool) {
+	if c == nil || c.cfg.Disabled || (c.cfg.TTL <= 0 && !c.cfg.Offline) {
+		return nil, time.Time{}, false
+	}
+	raw, err := os.ReadFile(c.path(k))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, time.Time{}, false
+	}
+	if !c.cfg.Offline && time.Since(e.StoredAt) > c.cfg.TTL {
+		return nil, time.Time{}, false
+	}
+	return e.Content, e.StoredAt, true
+}
+
+// Put stores a content snapshot for k when a TTL is set
+func (c *Cache) Put(k Key, data []byte) error {
+	if c == nil || c.cfg.Disabled || c.cfg.Offline || c.cfg.TTL <= 0 {
+		return nil
+	}
+	raw, err := json.Marshal(entry{Key: k, StoredAt: time.Now(), Content: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(k)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Digest returns a hex sha256 of v's JSON encoding, so values such as
+// credentials can take part in a key without being stored; empty values
+// (nil, "", empty maps and slices) digest to ""
+func Digest(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	switch string(raw) {
+	case "null", `""`, "{}", "[]":
+		return ""
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the entry file for k: <dir>/<hash[:2]>/<hash>.json
+func (c *Cache) path(k Key) string {
+	// encoding/json sorts map keys, so equal keys always hash the same
+	raw, _ := json.Marshal(k)
+	sum := sha256.Sum256(raw)
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.cfg.Dir, name[:2], name+".json")
+}
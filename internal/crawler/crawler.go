@@ -0,0 +1,302 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"durl/internal/formatter"
+	"durl/internal/politeness"
+	"durl/internal/scraper"
+	generic "durl/internal/sites/generic"
+)
+
+// Config holds crawl configuration
+type Config struct {
+	MaxDepth    int    // link depth followed from the seeds (0 = seeds only)
+	MaxPages    int    // stop after this many pages (<= 0 for no limit)
+	Concurrency int    // pages fetched in parallel
+	Scope       Scope  // which links are followed
+	OutputDir   string // pages and manifest.json are written here
+	Format      string // output format for page files (markdown, json, html, text)
+}
+
+// Page records the outcome of crawling one URL
+type Page struct {
+	URL     string `json:"url"`
+	Depth   int    `json:"depth"`
+	Parent  string `json:"parent,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	File    string `json:"file,omitempty"`    // relative to the output directory
+	Links   int    `json:"links"`             // in-scope links discovered on the page
+	Skipped bool   `json:"skipped,omitempty"` // disallowed by robots.txt, not fetched
+	Error   string `json:"error,omitempty"`
+}
+
+// Manifest summarizes a crawl and is written to manifest.json
+type Manifest struct {
+	Seeds      []string  `json:"seeds"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Pages      []Page    `json:"pages"`
+}
+
+// Crawler follows links from seed URLs within a scope, extracting each page
+// with the generic Fetcher and Extractor
+type Crawler struct {
+	cfg  Config
+	opts scraper.Options
+	wait []generic.WaitStep // parsed from opts.WaitFor and opts.WaitTargets
+
+	mu       sync.Mutex
+	seen     map[string]bool // normalized URLs already queued
+	files    map[string]bool // output files already written
+	manifest Manifest
+}
+
+// queued is a URL waiting to be crawled
+type queued struct {
+	url    *url.URL
+	depth  int
+	parent string
+}
+
+// New creates a Crawler; opts supplies fetch and extraction settings
+// (wait strategy, timeout, level, selector, browser pool and config) and
+// the politeness policy applied before every request
+func New(cfg Config, opts scraper.Options) *Crawler {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Format == "" {
+		cfg.Format = "markdown"
+	}
+	return &Crawler{
+		cfg:   cfg,
+		opts:  opts,
+		seen:  make(map[string]bool),
+		files: make(map[string]bool),
+	}
+}
+
+// Run crawls breadth-first from seeds, writing one file per page plus
+// manifest.json to the output directory. Per-page failures are recorded in
+// the manifest and do not stop the crawl.
+func (c *Crawler) Run(ctx context.Context, seeds []string) (*Manifest, error) {
+	wait, err := generic.WaitSteps(c.opts)
+	if err != nil {
+		return nil, err
+	}
+	c.wait = wait
+
+	if err := os.MkdirAll(c.cfg.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	c.manifest = Manifest{Seeds: seeds, StartedAt: time.Now()}
+
+	// Without explicit hosts, the crawl stays on the seeds' hosts
+	defaultHosts := len(c.cfg.Scope.Hosts) == 0
+
+	var level []queued
+	for _, s := range seeds {
+		u, ok := Normalize(s, nil)
+		if !ok {
+			return nil, fmt.Errorf("invalid seed URL: %s", s)
+		}
+		if defaultHosts {
+			c.cfg.Scope.Hosts = append(c.cfg.Scope.Hosts, u.Hostname())
+		}
+		if c.markSeen(u) {
+			level = append(level, queued{url: u})
+		}
+	}
+
+	b, err := c.opts.Pool.Acquire(c.opts.BrowserConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create browser: %w", err)
+	}
+	defer b.Close()
+	if err := c.opts.Login.Run(b, c.opts.Timeout); err != nil {
+		return nil, err
+	}
+	fetcher := generic.NewFetcher(b)
+	fetcher.SetActions(c.opts.Actions)
+
+	crawled := 0
+	for depth := 0; len(level) > 0 && depth <= c.cfg.MaxDepth; depth++ {
+		if c.cfg.MaxPages > 0 && crawled+len(level) > c.cfg.MaxPages {
+			level = level[:c.cfg.MaxPages-crawled]
+		}
+		crawled += len(level)
+
+		next := c.crawlLevel(ctx, fetcher, level)
+		if c.cfg.MaxPages > 0 && crawled >= c.cfg.MaxPages {
+			break
+		}
+		level = next
+	}
+
+	c.manifest.FinishedAt = time.Now()
+	if err := c.writeManifest(); err != nil {
+		return &c.manifest, err
+	}
+	return &c.manifest, nil
+}
+
+// crawlLevel fetches all URLs of one depth with bounded concurrency and
+// returns the in-scope links discovered for the next depth
+func (c *Crawler) crawlLevel(ctx context.Context, fetcher *generic.Fetcher, level []queued) []queued {
+	var next []queued
+	var nextMu sync.Mutex
+
+	jobs := make(chan queued)
+	var wg sync.WaitGroup
+	for w := 0; w < c.cfg.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for q := range jobs {
+				page, links := c.crawlOne(ctx, fetcher, q)
+
+				nextMu.Lock()
+				next = append(next, links...)
+				nextMu.Unlock()
+
+				c.mu.Lock()
+				c.manifest.Pages = append(c.manifest.Pages, page)
+				c.mu.Unlock()
+			}
+		}()
+	}
+	for _, q := range level {
+		jobs <- q
+	}
+	close(jobs)
+	wg.Wait()
+
+	return next
+}
+
+// crawlOne fetches, extracts and writes a single page
+func (c *Crawler) crawlOne(ctx context.Context, fetcher *generic.Fetcher, q queued) (Page, []queued) {
+	target := q.url.String()
+	page := Page{URL: target, Depth: q.depth, Parent: q.parent}
+	if err := ctx.Err(); err != nil {
+		page.Error = err.Error()
+		return page, nil
+	}
+	if err := c.opts.Politeness.Wait(ctx, target); err != nil {
+		page.Skipped = errors.Is(err, politeness.ErrDisallowed)
+		page.Error = err.Error()
+		return page, nil
+	}
+	fmt.Fprintf(os.Stderr, "[crawl] depth %d: %s\n", q.depth, target)
+
+	result, err := fetcher.Fetch(target, "GET", c.opts.Headers, "", c.wait, c.opts.Timeout)
+	if err != nil {
+		page.Error = err.Error()
+		return page, nil
+	}
+	defer result.Page.Close()
+	page.Status = result.Response.StatusCode
+
+	content, err := generic.ExtractPage(result, c.opts)
+	if err != nil {
+		page.Error = err.Error()
+		return page, nil
+	}
+	page.Title = result.Title
+
+	file, err := c.writePage(q.url, content)
+	if err != nil {
+		page.Error = err.Error()
+	}
+	page.File = file
+
+	var next []queued
+	if q.depth < c.cfg.MaxDepth {
+		// Resolve links against the final URL so redirects are honoured
+		base, ok := Normalize(result.URL, nil)
+		if !ok {
+			base = q.url
+		}
+		links, err := generic.NewExtractor(result.Page).Links()
+		if err != nil && page.Error == "" {
+			page.Error = err.Error()
+		}
+		for _, raw := range links {
+			u, ok := Normalize(raw, base)
+			if !ok || !c.cfg.Scope.Allows(u) || !c.markSeen(u) {
+				continue
+			}
+			next = append(next, queued{url: u, depth: q.depth + 1, parent: target})
+		}
+	}
+	page.Links = len(next)
+	return page, next
+}
+
+// markSeen records u and reports whether it was new
+func (c *Crawler) markSeen(u *url.URL) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := u.String()
+	if c.seen[key] {
+		return false
+	}
+	c.seen[key] = true
+	return true
+}
+
+// writePage formats content and writes it under <host>/<slug>.<ext>,
+// returning the path relative to the output directory
+func (c *Crawler) writePage(u *url.URL, content scraper.Content) (string, error) {
+	out, err := formatter.Format(content, c.cfg.Format)
+	if err != nil {
+		return "", fmt.Errorf("failed to format page: %w", err)
+	}
+
+	slug := formatter.Slug(strings.TrimPrefix(u.Path, "/") + " " + u.RawQuery)
+	if slug == "" {
+		slug = "index"
+	}
+	host := formatter.Slug(u.Host)
+
+	c.mu.Lock()
+	rel := filepath.Join(host, slug+formatter.Extension(c.cfg.Format))
+	for i := 2; c.files[rel]; i++ {
+		rel = filepath.Join(host, fmt.Sprintf("%s-%d%s", slug, i, formatter.Extension(c.cfg.Format)))
+	}
+	c.files[rel] = true
+	c.mu.Unlock()
+
+	full := filepath.Join(c.cfg.OutputDir, rel)
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(full, []byte(out), 0644); err != nil {
+		return "", fmt.Errorf("failed to write page: %w", err)
+	}
+	return rel, nil
+}
+
+// writeManifest writes manifest.json to the output directory
+func (c *Crawler) writeManifest() error {
+	data, err := json.MarshalIndent(c.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.cfg.OutputDir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// skippedExtensions are links to non-HTML resources that are never crawled
+var skippedExtensions = map[string]bool{
+	".pdf": true, ".zip": true, ".gz": true, ".rar": true, ".7z": true, ".exe": true, ".dmg": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".webp": true, ".svg": true, ".ico": true,
+	".mp3": true, ".mp4": true, ".avi": true, ".mov": true, ".webm": true,
+	".css": true, ".js": true, ".json": true, ".xml": true, ".woff": true, ".woff2": true, ".ttf": true,
+}
+
+// Scope decides which discovered links are followed
+type Scope struct {
+	Hosts      []string         // allowed hosts; a leading "." also allows subdomains
+	PathPrefix string           // URL path must start with this prefix (empty = any)
+	Include    []*regexp.Regexp // if set, the URL must match at least one
+	Exclude    []*regexp.Regexp // the URL must match none
+}
+
+// Allows reports whether the normalized URL u is inside the crawl scope
+func (s *Scope) Allows(u *url.URL) bool {
+	if skippedExtensions[strings.ToLower(path.Ext(u.Path))] {
+		return false
+	}
+
+	if len(s.Hosts) > 0 && !s.allowsHost(u.Hostname()) {
+		return false
+	}
+
+	if s.PathPrefix != "" && !strings.HasPrefix(u.Path, s.PathPrefix) {
+		return false
+	}
+
+	raw := u.String()
+	for _, re := range s.Exclude {
+		if re.MatchString(raw) {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, re := range s.Include {
+		if re.MatchString(raw) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scope) allowsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, h := range s.Hosts {
+		h = strings.ToLower(h)
+		if strings.HasPrefix(h, ".") {
+			if host == h[1:] || strings.HasSuffix(host, h) {
+				return true
+			}
+		} else if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// Normalize resolves raw against base and canonicalizes it for deduplication:
+// http(s) only, lower-case host, no default port, no fragment, no utm_*
+// tracking parameters, sorted query. ok is false for unusable links.
+func Normalize(raw string, base *url.URL) (u *url.URL, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, false
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return nil, false
+	}
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if strings.HasPrefix(strings.ToLower(k), "utm_") {
+				q.Del(k)
+			}
+		}
+		u.RawQuery = q.Encode() // Encode sorts by key
+	}
+
+	return u, true
+}
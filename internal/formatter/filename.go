@@ -0,0 +1,58 @@
+package formatter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Extension returns the file extension for an output format
+func Extension(format string) string {
+	switch format {
+	case "markdown":
+		return ".md"
+	case "json":
+		return ".json"
+	case "html":
+		return ".html"
+	case "csv":
+		return ".csv"
+	case "png":
+		return ".png"
+	case "jpeg":
+		return ".jpg"
+	case "pdf":
+		return ".pdf"
+	case "mhtml":
+		return ".mhtml"
+	case "archive":
+		return ".html"
+	default:
+		return ".txt"
+	}
+}
+
+// Slug lowercases s and collapses runs of characters other than letters,
+// digits and dots into single dashes, keeping at most 80 runes, so it can be
+// used as a file name
+func Slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	n := 0
+	for _, r := range strings.ToLower(s) {
+		if n >= 80 {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' {
+			sb.WriteRune(r)
+			dash = false
+			n++
+			continue
+		}
+		if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+			n++
+		}
+	}
+	return strings.Trim(sb.String(), "-.")
+}
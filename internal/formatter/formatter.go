@@ -6,8 +6,16 @@ import (
 	"durl/internal/scraper"
 )
 
+// Format renders content in the given output format. png, jpeg, pdf, mhtml
+// and archive return the capture taken during the scrape.
 func Format(content scraper.Content, format string) (string, error) {
 	switch format {
+	case "png", "jpeg", "pdf", "mhtml", "archive":
+		c, ok := content.(scraper.Capturer)
+		if !ok || c.Capture() == nil {
+			return "", fmt.Errorf("%s output is only available in generic mode", format)
+		}
+		return string(c.Capture()), nil
 	case "html":
 		return content.ToHTML()
 	case "text":
@@ -0,0 +1,269 @@
+// Package har records the network traffic of browser pages from CDP Network
+// events and writes it as a HAR 1.2 file.
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HAR is the root of a HAR 1.2 document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded pages and requests
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator names the application that wrote the file
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Page is one browser page (tab) the requests belong to
+type Page struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	ID              string      `json:"id"`
+	Title           string      `json:"title"`
+	PageTimings     PageTimings `json:"pageTimings"`
+}
+
+// PageTimings are milliseconds since the page started; -1 if not reached
+type PageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+// Entry is one request/response pair
+type Entry struct {
+	PageRef         string   `json:"pageref,omitempty"`
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	ResourceType    string   `json:"_resourceType,omitempty"`
+	Error           string   `json:"_error,omitempty"` // net error of a failed request
+}
+
+// Request is the HAR request object
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Response is the HAR response object
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Content describes the response body; Text is set when bodies are recorded
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// PostData is the request body
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Params   []NameValue `json:"params"`
+	Text     string      `json:"text"`
+}
+
+// NameValue is a header, cookie or query parameter
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Timings are the request phases in milliseconds; -1 if not applicable
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// Recorder collects entries from every page attached to it. It is safe for
+// concurrent use by the pages of a batch or crawl.
+type Recorder struct {
+	version string
+	bodies  bool
+
+	mu      sync.Mutex
+	pages   []*pageRecorder
+	pending sync.WaitGroup // in-flight response body fetches
+}
+
+// New creates a recorder; bodies also stores response bodies
+func New(version string, bodies bool) *Recorder {
+	return &Recorder{version: version, bodies: bodies}
+}
+
+// HAR stops recording and returns the document. Entries are ordered by
+// start time.
+func (r *Recorder) HAR() *HAR {
+	r.mu.Lock()
+	pages := append([]*pageRecorder{}, r.pages...)
+	r.mu.Unlock()
+	for _, p := range pages {
+		p.stop()
+	}
+	r.pending.Wait()
+
+	h := &HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "durl", Version: r.version},
+		Pages:   []Page{},
+		Entries: []Entry{},
+	}}
+	for _, p := range pages {
+		page, entries := p.snapshot()
+		h.Log.Pages = append(h.Log.Pages, page)
+		h.Log.Entries = append(h.Log.Entries, entries...)
+	}
+	sort.SliceStable(h.Log.Entries, func(i, j int) bool {
+		return h.Log.Entries[i].StartedDateTime < h.Log.Entries[j].StartedDateTime
+	})
+	return h
+}
+
+// WriteFile stops recording and writes the HAR file
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write HAR: %w", err)
+	}
+	return nil
+}
+
+// headerList converts a header map into sorted name/value pairs. CDP joins
+// repeated headers with newlines; each becomes its own pair.
+func headerList(headers map[string]string) []NameValue {
+	list := []NameValue{}
+	for name, value := range headers {
+		for _, v := range strings.Split(value, "\n") {
+			list = append(list, NameValue{Name: name, Value: v})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+		return list[i].Value < list[j].Value
+	})
+	return list
+}
+
+// headerValue looks a header up case-insensitively
+func headerValue(headers map[string]string, name string) string {
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// queryString lists the query parameters of rawURL
+func queryString(rawURL string) []NameValue {
+	list := []NameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return list
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		list = append(list, NameValue{Name: name, Value: value})
+	}
+	return list
+}
+
+// cookieList parses a Cookie request header
+func cookieList(header string) []NameValue {
+	list := []NameValue{}
+	for _, part := range strings.Split(header, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && name != "" {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
+
+// setCookieList parses Set-Cookie response headers (newline-joined)
+func setCookieList(header string) []NameValue {
+	list := []NameValue{}
+	for _, line := range strings.Split(header, "\n") {
+		first, _, _ := strings.Cut(line, ";")
+		name, value, ok := strings.Cut(strings.TrimSpace(first), "=")
+		if ok && name != "" {
+			list = append(list, NameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
+
+// httpVersion maps a CDP protocol name to a HAR httpVersion
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "":
+		return ""
+	case "h2":
+		return "HTTP/2.0"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3.0"
+	}
+	return strings.ToUpper(protocol)
+}
+
+// isoTime formats t the way HAR expects
+func isoTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,289 @@
+package har
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// pageRecorder collects the entries of one page
+type pageRecorder struct {
+	rec    *Recorder
+	page   *rod.Page
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	info    Page
+	startTS proto.MonotonicTime // monotonic time of the first request
+	entries []*entry
+	byID    map[proto.NetworkRequestID]*entry
+}
+
+// entry is an Entry under construction
+type entry struct {
+	Entry
+	startTS proto.MonotonicTime
+	timing  *proto.NetworkResourceTiming
+}
+
+// Attach records the network traffic of page until the HAR is taken. The
+// page is attached before it navigates so the document request is seen.
+func (r *Recorder) Attach(page *rod.Page) {
+	if r == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(page.GetContext())
+
+	r.mu.Lock()
+	p := &pageRecorder{
+		rec:    r,
+		page:   page,
+		cancel: cancel,
+		info: Page{
+			ID:          fmt.Sprintf("page_%d", len(r.pages)+1),
+			PageTimings: PageTimings{OnContentLoad: -1, OnLoad: -1},
+		},
+		byID: map[proto.NetworkRequestID]*entry{},
+	}
+	r.pages = append(r.pages, p)
+	r.mu.Unlock()
+
+	wait := page.Context(ctx).EachEvent(
+		p.requestWillBeSent,
+		p.responseReceived,
+		p.loadingFinished,
+		p.loadingFailed,
+		func(e *proto.PageDomContentEventFired) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.info.PageTimings.OnContentLoad = p.sinceStart(e.Timestamp)
+		},
+		func(e *proto.PageLoadEventFired) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.info.PageTimings.OnLoad = p.sinceStart(e.Timestamp)
+		},
+	)
+	go wait()
+}
+
+func (p *pageRecorder) requestWillBeSent(e *proto.NetworkRequestWillBeSent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// A redirect reuses the request ID: complete the previous hop first
+	if prev, ok := p.byID[e.RequestID]; ok && e.RedirectResponse != nil {
+		prev.applyResponse(e.RedirectResponse)
+		prev.Response.RedirectURL = e.Request.URL
+		prev.finish(e.Timestamp, e.RedirectResponse.EncodedDataLength)
+	}
+
+	wall := e.WallTime.Time()
+	if len(p.entries) == 0 {
+		p.startTS = e.Timestamp
+		p.info.StartedDateTime = isoTime(wall)
+		p.info.Title = e.Request.URL
+	}
+
+	headers := jsonHeaders(e.Request.Headers)
+	en := &entry{startTS: e.Timestamp}
+	en.PageRef = p.info.ID
+	en.StartedDateTime = isoTime(wall)
+	en.ResourceType = string(e.Type)
+	en.Request = Request{
+		Method:      e.Request.Method,
+		URL:         e.Request.URL,
+		Cookies:     cookieList(headerValue(headers, "Cookie")),
+		Headers:     headerList(headers),
+		QueryString: queryString(e.Request.URL),
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+	if e.Request.HasPostData {
+		en.Request.PostData = &PostData{
+			MimeType: headerValue(headers, "Content-Type"),
+			Params:   []NameValue{},
+			Text:     e.Request.PostData,
+		}
+		en.Request.BodySize = len(e.Request.PostData)
+	}
+	en.Response = Response{
+		Cookies:     []NameValue{},
+		Headers:     []NameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	en.Timings = Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	p.entries = append(p.entries, en)
+	p.byID[e.RequestID] = en
+}
+
+func (p *pageRecorder) responseReceived(e *proto.NetworkResponseReceived) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if en, ok := p.byID[e.RequestID]; ok {
+		en.applyResponse(e.Response)
+	}
+}
+
+func (p *pageRecorder) loadingFinished(e *proto.NetworkLoadingFinished) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	en, ok := p.byID[e.RequestID]
+	if !ok {
+		return
+	}
+	en.finish(e.Timestamp, e.EncodedDataLength)
+
+	if p.rec.bodies && !p.stopped {
+		p.rec.pending.Add(1)
+		go p.fetchBody(e.RequestID, en)
+	}
+}
+
+func (p *pageRecorder) loadingFailed(e *proto.NetworkLoadingFailed) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if en, ok := p.byID[e.RequestID]; ok {
+		en.Error = e.ErrorText
+		en.finish(e.Timestamp, 0)
+	}
+}
+
+// fetchBody stores the response body of a finished request
+func (p *pageRecorder) fetchBody(id proto.NetworkRequestID, en *entry) {
+	defer p.rec.pending.Done()
+	res, err := proto.NetworkGetResponseBody{RequestID: id}.Call(p.page)
+	if err != nil {
+		// Redirects, preflights and evicted resources have no body
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	en.Response.Content.Text = res.Body
+	en.Response.Content.Size = len(res.Body)
+	if res.Base64Encoded {
+		en.Response.Content.Encoding = "base64"
+		en.Response.Content.Size = len(res.Body) * 3 / 4
+	}
+}
+
+// sinceStart converts a monotonic timestamp to milliseconds since the first request
+func (p *pageRecorder) sinceStart(ts proto.MonotonicTime) float64 {
+	if p.startTS == 0 {
+		return -1
+	}
+	return ms(float64(ts - p.startTS))
+}
+
+// stop ends event delivery; no body fetches start after it returns
+func (p *pageRecorder) stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	p.cancel()
+}
+
+// snapshot returns copies of the page and its entries
+func (p *pageRecorder) snapshot() (Page, []Entry) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := make([]Entry, len(p.entries))
+	for i, en := range p.entries {
+		entries[i] = en.Entry
+	}
+	return p.info, entries
+}
+
+// applyResponse copies the response fields of a (possibly redirect) response
+func (en *entry) applyResponse(r *proto.NetworkResponse) {
+	headers := jsonHeaders(r.Headers)
+	en.Response.Status = r.Status
+	en.Response.StatusText = r.StatusText
+	en.Response.HTTPVersion = httpVersion(r.Protocol)
+	en.Response.Headers = headerList(headers)
+	en.Response.Cookies = setCookieList(headerValue(headers, "Set-Cookie"))
+	en.Response.Content.MimeType = r.MIMEType
+	en.Request.HTTPVersion = en.Response.HTTPVersion
+	en.ServerIPAddress = r.RemoteIPAddress
+	en.timing = r.Timing
+
+	// The headers actually sent, including cookies, when the browser reports them
+	if len(r.RequestHeaders) > 0 {
+		sent := jsonHeaders(r.RequestHeaders)
+		en.Request.Headers = headerList(sent)
+		en.Request.Cookies = cookieList(headerValue(sent, "Cookie"))
+	}
+}
+
+// finish computes the timings of a completed (or failed) request
+func (en *entry) finish(end proto.MonotonicTime, encodedLength float64) {
+	total := ms(float64(end - en.startTS))
+	t := Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+
+	if tm := en.timing; tm != nil {
+		// Phase offsets are milliseconds relative to tm.RequestTime
+		queued := ms(tm.RequestTime - float64(en.startTS))
+		first := tm.SendStart
+		for _, v := range []float64{tm.ConnectStart, tm.DNSStart} {
+			if v >= 0 {
+				first = v
+			}
+		}
+		t.Blocked = nonNegative(queued + first)
+		if tm.DNSStart >= 0 {
+			t.DNS = tm.DNSEnd - tm.DNSStart
+		}
+		if tm.ConnectStart >= 0 {
+			t.Connect = tm.ConnectEnd - tm.ConnectStart
+		}
+		if tm.SslStart >= 0 {
+			t.SSL = tm.SslEnd - tm.SslStart
+		}
+		t.Send = nonNegative(tm.SendEnd - tm.SendStart)
+		t.Wait = nonNegative(tm.ReceiveHeadersEnd - tm.SendEnd)
+		t.Receive = nonNegative(total - queued - tm.ReceiveHeadersEnd)
+	} else {
+		t.Receive = nonNegative(total)
+	}
+
+	en.Timings = t
+	en.Time = 0
+	// ssl is included in connect, so it is not added again
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			en.Time += v
+		}
+	}
+	if encodedLength > 0 && en.Response.Content.Size == 0 {
+		en.Response.Content.Size = int(encodedLength)
+	}
+}
+
+// jsonHeaders converts CDP headers to strings
+func jsonHeaders(h proto.NetworkHeaders) map[string]string {
+	headers := make(map[string]string, len(h))
+	for k, v := range h {
+		headers[k] = v.String()
+	}
+	return headers
+}
+
+// ms converts seconds to milliseconds, rounded to microseconds
+func ms(seconds float64) float64 {
+	return float64(time.Duration(seconds*float64(time.Second)).Round(time.Microsecond)) / float64(time.Millisecond)
+}
+
+func nonNegative(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
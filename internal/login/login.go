@@ -0,0 +1,301 @@
+// Package login signs in through a username/password form before the
+// target is fetched, so content behind simple logins can be scraped.
+package login
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"durl/internal/browser"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Flow describes a login form and how to tell that it succeeded
+type Flow struct {
+	URL     string  `yaml:"url"`     // login page
+	Fields  []Field `yaml:"fields"`  // inputs filled in order
+	Submit  string  `yaml:"submit"`  // selector clicked to submit; empty presses Enter in the last field
+	Success Success `yaml:"success"` // all given conditions must hold once logged in
+	Secrets string  `yaml:"secrets"` // KEY=VALUE file, relative to the login file, consulted before the environment
+
+	hash string
+	host string
+	url  *regexp.Regexp
+
+	mu      sync.Mutex
+	session []*proto.NetworkCookieParam // cookies after logging in, reused by later browser contexts
+}
+
+// Field is one form input. Value may reference ${NAME} from the secrets
+// file or the environment, so credentials stay out of the login file.
+type Field struct {
+	Selector string `yaml:"selector"`
+	Value    string `yaml:"value"`
+
+	value string // Value with references resolved
+}
+
+// Success tells a logged-in page apart
+type Success struct {
+	URL      string `yaml:"url"`      // regular expression the page URL matches
+	Selector string `yaml:"selector"` // element present only when logged in
+	Text     string `yaml:"text"`     // text present only when logged in
+	Cookie   string `yaml:"cookie"`   // session cookie set for the login host
+}
+
+// Load reads a YAML or JSON login file and resolves its credentials
+func Load(path string) (*Flow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read login: %w", err)
+	}
+	var f Flow
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse login %s: %w", path, err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("invalid login %s: %w", path, err)
+	}
+
+	secrets := map[string]string{}
+	if f.Secrets != "" {
+		secretsPath := f.Secrets
+		if !filepath.IsAbs(secretsPath) {
+			secretsPath = filepath.Join(filepath.Dir(path), secretsPath)
+		}
+		if secrets, err = loadSecrets(secretsPath); err != nil {
+			return nil, err
+		}
+	}
+	for i := range f.Fields {
+		v, err := resolve(f.Fields[i].Value, secrets)
+		if err != nil {
+			return nil, fmt.Errorf("invalid login %s: field %q: %w", path, f.Fields[i].Selector, err)
+		}
+		f.Fields[i].value = v
+	}
+
+	// Credentials from the secrets file or environment change the hash too,
+	// so sessions of different accounts are never mixed up
+	h := sha256.New()
+	h.Write(data)
+	for _, field := range f.Fields {
+		h.Write([]byte{0})
+		h.Write([]byte(field.value))
+	}
+	f.hash = hex.EncodeToString(h.Sum(nil))
+	return &f, nil
+}
+
+// Hash identifies the login file contents and the resolved credentials
+// without revealing them
+func (f *Flow) Hash() string {
+	return f.hash
+}
+
+func (f *Flow) validate() error {
+	u, err := url.Parse(f.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	f.host = strings.ToLower(u.Hostname())
+	if len(f.Fields) == 0 {
+		return fmt.Errorf("no fields defined")
+	}
+	for _, field := range f.Fields {
+		if field.Selector == "" {
+			return fmt.Errorf("field without a selector")
+		}
+	}
+	s := f.Success
+	if s.URL == "" && s.Selector == "" && s.Text == "" && s.Cookie == "" {
+		return fmt.Errorf("success requires url, selector, text or cookie")
+	}
+	if s.URL != "" {
+		if f.url, err = regexp.Compile(s.URL); err != nil {
+			return fmt.Errorf("invalid success url: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run logs in within b unless the session is already valid: cookies from an
+// earlier login of this run are restored first, and a session cookie loaded
+// from a cookie jar or profile skips the form entirely. The cookies end up in
+// the browser context, so -c/--cookie-jar saves them for later runs.
+func (f *Flow) Run(b *browser.Browser, timeout time.Duration) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := b.SetCookies(f.session); err != nil {
+		return fmt.Errorf("failed to restore login session: %w", err)
+	}
+	if f.Success.Cookie != "" && f.hasCookie(b) {
+		return nil
+	}
+
+	// The form post carries the credentials, so it stays out of the HAR
+	page, err := b.NewUnrecordedPage()
+	if err != nil {
+		return fmt.Errorf("failed to create page: %w", err)
+	}
+	defer page.Close()
+	p := page.Timeout(timeout)
+
+	fmt.Fprintf(os.Stderr, "[login] opening %s\n", f.URL)
+	if err := p.Navigate(f.URL); err != nil {
+		return fmt.Errorf("failed to open login page: %w", err)
+	}
+	_ = p.WaitLoad()
+
+	if f.loggedIn(b, p) {
+		fmt.Fprintf(os.Stderr, "[login] already logged in\n")
+		return f.keepSession(b)
+	}
+
+	var last *rod.Element
+	for _, field := range f.Fields {
+		el, err := p.Element(field.Selector)
+		if err != nil {
+			return fmt.Errorf("login field %q not found: %w", field.Selector, err)
+		}
+		if err := el.SelectAllText(); err != nil {
+			return fmt.Errorf("failed to fill login field %q: %w", field.Selector, err)
+		}
+		if err := el.Input(field.value); err != nil {
+			return fmt.Errorf("failed to fill login field %q: %w", field.Selector, err)
+		}
+		last = el
+	}
+
+	if f.Submit != "" {
+		el, err := p.Element(f.Submit)
+		if err != nil {
+			return fmt.Errorf("login submit %q not found: %w", f.Submit, err)
+		}
+		if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return fmt.Errorf("failed to submit login: %w", err)
+		}
+	} else if err := last.Type(input.Enter); err != nil {
+		return fmt.Errorf("failed to submit login: %w", err)
+	}
+
+	// Poll the success condition; evaluation errors while the next page
+	// loads count as not logged in yet
+	for !f.loggedIn(b, p) {
+		select {
+		case <-time.After(250 * time.Millisecond):
+		case <-p.GetContext().Done():
+			return fmt.Errorf("login failed: success condition not met within %s", timeout)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "[login] logged in to %s\n", f.host)
+	return f.keepSession(b)
+}
+
+// loggedIn reports whether every configured success condition holds
+func (f *Flow) loggedIn(b *browser.Browser, p *rod.Page) bool {
+	s := f.Success
+	if f.url != nil {
+		info, err := p.Info()
+		if err != nil || !f.url.MatchString(info.URL) {
+			return false
+		}
+	}
+	if s.Selector != "" {
+		if has, _, err := p.Has(s.Selector); err != nil || !has {
+			return false
+		}
+	}
+	if s.Text != "" {
+		res, err := p.Eval(`t => !!document.body && document.body.innerText.includes(t)`, s.Text)
+		if err != nil || !res.Value.Bool() {
+			return false
+		}
+	}
+	if s.Cookie != "" && !f.hasCookie(b) {
+		return false
+	}
+	return true
+}
+
+// hasCookie reports whether the success cookie is set for the login host
+func (f *Flow) hasCookie(b *browser.Browser) bool {
+	return b.HasCookie(f.host, f.Success.Cookie)
+}
+
+// keepSession remembers the context's cookies for later browser contexts
+func (f *Flow) keepSession(b *browser.Browser) error {
+	cookies, err := b.Cookies()
+	if err != nil {
+		return fmt.Errorf("failed to read login session: %w", err)
+	}
+	f.session = proto.CookiesToParams(cookies)
+	return nil
+}
+
+// resolve expands ${NAME} references from secrets, then the environment
+func resolve(value string, secrets map[string]string) (string, error) {
+	var missing []string
+	v := os.Expand(value, func(name string) string {
+		if s, ok := secrets[name]; ok {
+			return s
+		}
+		if s, ok := os.LookupEnv(name); ok {
+			return s
+		}
+		missing = append(missing, name)
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s is not set in the secrets file or environment", strings.Join(missing, ", "))
+	}
+	return v, nil
+}
+
+// loadSecrets reads KEY=VALUE lines; blank lines and # comments are skipped
+func loadSecrets(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	defer file.Close()
+
+	secrets := map[string]string{}
+	sc := bufio.NewScanner(file)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid secrets %s: line %d is not KEY=VALUE", path, n)
+		}
+		v = strings.TrimSpace(v)
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		secrets[strings.TrimSpace(k)] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,181 @@
+// Package metadata reads the metadata a page declares: meta tags, the
+// canonical link, language, JSON-LD and microdata. The generic scraper
+// reports it and Readability derives the article byline, excerpt and dates
+// from it, so a document is only scanned once.
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata is the document-level metadata declared by a page
+type Metadata struct {
+	Description string            `json:"description,omitempty"`
+	Canonical   string            `json:"canonical,omitempty"`
+	Language    string            `json:"language,omitempty"`
+	OpenGraph   map[string]string `json:"open_graph,omitempty"` // og:* and article:* properties
+	Twitter     map[string]string `json:"twitter,omitempty"`    // twitter:* card tags
+	JSONLD      []json.RawMessage `json:"json_ld,omitempty"`    // each valid application/ld+json block
+	Microdata   []MicrodataItem   `json:"microdata,omitempty"`  // top-level itemscope items
+
+	// Tags holds every meta tag content by lowercase property, name and
+	// itemprop (each space-separated name); the first tag for a name wins.
+	// Dublin Core names are keyed with a colon (dc:title, dcterms:created)
+	// whether the page writes dc.title or dc:title.
+	Tags map[string]string `json:"-"`
+}
+
+// MicrodataItem is one schema.org microdata item. Property values are
+// strings, or nested *MicrodataItem values for itemscope properties.
+type MicrodataItem struct {
+	Type       []string         `json:"type,omitempty"`
+	ID         string           `json:"id,omitempty"`
+	Properties map[string][]any `json:"properties"`
+}
+
+// Extract parses the rendered document and collects its metadata.
+// pageURL resolves a relative canonical link.
+func Extract(htmlContent, pageURL string) Metadata {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return Metadata{}
+	}
+	return Parse(doc, pageURL)
+}
+
+// tagName spells Dublin Core meta names (dc.title, dcterms.title) with a
+// colon like the other namespaced names
+func tagName(name string) string {
+	for _, prefix := range []string{"dc.", "dcterms."} {
+		if strings.HasPrefix(name, prefix) {
+			return strings.TrimSuffix(prefix, ".") + ":" + name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// Parse collects the metadata of a parsed document
+func Parse(doc *goquery.Document, pageURL string) Metadata {
+	var m Metadata
+	doc.Find("meta").Each(func(_ int, s *goquery.Selection) {
+		content := strings.TrimSpace(s.AttrOr("content", ""))
+		if content == "" {
+			return
+		}
+		for _, attr := range []string{"property", "name", "itemprop"} {
+			for _, name := range strings.Fields(strings.ToLower(s.AttrOr(attr, ""))) {
+				m.Tags = setFirst(m.Tags, tagName(name), content)
+			}
+		}
+
+		key := strings.ToLower(strings.TrimSpace(s.AttrOr("property", s.AttrOr("name", ""))))
+		switch {
+		case key == "description":
+			if m.Description == "" {
+				m.Description = content
+			}
+		case strings.HasPrefix(key, "og:") || strings.HasPrefix(key, "article:"):
+			m.OpenGraph = setFirst(m.OpenGraph, key, content)
+		case strings.HasPrefix(key, "twitter:"):
+			m.Twitter = setFirst(m.Twitter, key, content)
+		case strings.EqualFold(s.AttrOr("http-equiv", ""), "content-language"):
+			if m.Language == "" {
+				m.Language = content
+			}
+		}
+	})
+
+	if lang := strings.TrimSpace(doc.Find("html").AttrOr("lang", "")); lang != "" {
+		m.Language = lang
+	} else if m.Language == "" {
+		m.Language = m.OpenGraph["og:locale"]
+	}
+
+	if href := strings.TrimSpace(doc.Find(`link[rel~="canonical"]`).First().AttrOr("href", "")); href != "" {
+		m.Canonical = href
+		if base, err := url.Parse(pageURL); err == nil {
+			if ref, err := url.Parse(href); err == nil {
+				m.Canonical = base.ResolveReference(ref).String()
+			}
+		}
+	}
+
+	doc.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, []byte(strings.TrimSpace(s.Text()))); err == nil {
+			m.JSONLD = append(m.JSONLD, buf.Bytes())
+		}
+	})
+
+	doc.Find("[itemscope]").Each(func(_ int, s *goquery.Selection) {
+		// Items that are properties of another item are nested, not top-level
+		if _, isProp := s.Attr("itemprop"); !isProp {
+			m.Microdata = append(m.Microdata, *microdataItem(s))
+		}
+	})
+
+	return m
+}
+
+// microdataItem reads the properties that belong to the itemscope element s
+func microdataItem(s *goquery.Selection) *MicrodataItem {
+	item := &MicrodataItem{
+		Type:       strings.Fields(s.AttrOr("itemtype", "")),
+		ID:         s.AttrOr("itemid", ""),
+		Properties: map[string][]any{},
+	}
+	s.Find("[itemprop]").Each(func(_ int, p *goquery.Selection) {
+		// Skip properties owned by a nested item
+		if owner := p.Parent().Closest("[itemscope]"); owner.Length() == 0 || owner.Get(0) != s.Get(0) {
+			return
+		}
+		var value any
+		if _, scoped := p.Attr("itemscope"); scoped {
+			value = microdataItem(p)
+		} else {
+			value = microdataValue(p)
+		}
+		for _, name := range strings.Fields(p.AttrOr("itemprop", "")) {
+			item.Properties[name] = append(item.Properties[name], value)
+		}
+	})
+	return item
+}
+
+// microdataValue returns a property's value per the HTML microdata rules
+func microdataValue(p *goquery.Selection) string {
+	switch goquery.NodeName(p) {
+	case "meta":
+		return p.AttrOr("content", "")
+	case "audio", "embed", "iframe", "img", "source", "track", "video":
+		return p.AttrOr("src", "")
+	case "a", "area", "link":
+		return p.AttrOr("href", "")
+	case "object":
+		return p.AttrOr("data", "")
+	case "data", "meter":
+		return p.AttrOr("value", "")
+	case "time":
+		if v, ok := p.Attr("datetime"); ok {
+			return v
+		}
+	}
+	return strings.TrimSpace(p.Text())
+}
+
+// setFirst stores value under key unless the key is already set, so the
+// first of repeated tags (e.g. several og:image) wins
+func setFirst(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	if _, ok := m[key]; !ok {
+		m[key] = value
+	}
+	return m
+}
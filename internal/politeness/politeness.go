@@ -0,0 +1,186 @@
+package politeness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// Agent is the product token matched against robots.txt User-agent lines
+const Agent = "durl"
+
+// ErrDisallowed is returned for URLs excluded by the host's robots.txt
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Config holds politeness settings
+type Config struct {
+	Interval     time.Duration // minimum delay between requests to one host; robots Crawl-delay may raise it
+	Burst        int           // requests allowed back to back before Interval applies (default 1)
+	IgnoreRobots bool          // skip robots.txt entirely (Crawl-delay is ignored too)
+	Timeout      time.Duration // robots.txt fetch timeout (default 10s)
+	ProxyURL     string        // proxy for robots.txt fetches (--proxy or DURL_PROXY); empty goes direct
+}
+
+// Policy enforces robots.txt and a per-host token bucket. A nil *Policy
+// allows every request immediately.
+type Policy struct {
+	cfg    Config
+	client *http.Client
+
+	mu    sync.Mutex
+	hosts map[string]*hostState // keyed by scheme://host
+}
+
+// hostState caches a host's robots.txt and rate-limit bucket
+type hostState struct {
+	once   sync.Once
+	robots *Robots
+
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+// New creates a Policy
+func New(cfg Config) *Policy {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	if u, err := url.Parse(cfg.ProxyURL); err == nil && cfg.ProxyURL != "" {
+		client.Transport = &http.Transport{Proxy: http.ProxyURL(u)}
+	}
+	return &Policy{
+		cfg:    cfg,
+		client: client,
+		hosts:  make(map[string]*hostState),
+	}
+}
+
+// Wait checks target against the host's robots.txt and blocks until the
+// host's rate limit admits another request. It returns an error wrapping
+// ErrDisallowed for excluded URLs, an error for targets without a host,
+// or ctx's error if ctx ends first.
+func (p *Policy) Wait(ctx context.Context, target string) error {
+	if p == nil {
+		return nil
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("failed to apply politeness: %q is not a URL", target)
+	}
+
+	h := p.host(ctx, u)
+	if !h.robots.Allowed(u.RequestURI()) {
+		return fmt.Errorf("%w: %s", ErrDisallowed, target)
+	}
+	return h.take(ctx)
+}
+
+// host returns the state for u's host, fetching robots.txt on first use
+func (p *Policy) host(ctx context.Context, u *url.URL) *hostState {
+	key := u.Scheme + "://" + u.Host
+
+	p.mu.Lock()
+	h, ok := p.hosts[key]
+	if !ok {
+		h = &hostState{interval: p.cfg.Interval, burst: float64(p.cfg.Burst), tokens: float64(p.cfg.Burst)}
+		p.hosts[key] = h
+	}
+	p.mu.Unlock()
+
+	h.once.Do(func() {
+		if p.cfg.IgnoreRobots {
+			return
+		}
+		robots, err := p.fetchRobots(ctx, key)
+		if err != nil {
+			// Unreachable robots.txt does not block the crawl
+			fmt.Fprintf(os.Stderr, "[robots] %s: %v (allowing all)\n", key, err)
+			return
+		}
+		h.robots = robots
+		if robots.CrawlDelay > h.interval {
+			h.interval = robots.CrawlDelay
+		}
+	})
+	return h
+}
+
+// fetchRobots downloads and parses origin's robots.txt. A missing file (4xx)
+// yields empty rules.
+func (p *Policy) fetchRobots(ctx context.Context, origin string) (*Robots, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", Agent)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch robots.txt: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return nil, fmt.Errorf("failed to fetch robots.txt: %s", resp.Status)
+	case resp.StatusCode >= 400:
+		return &Robots{}, nil
+	}
+
+	// Cap the read like common crawlers do (500 KiB)
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 500<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read robots.txt: %w", err)
+	}
+	return ParseRobots(data, Agent), nil
+}
+
+// take consumes one token from the host's bucket, sleeping until one is
+// available. Tokens refill at one per interval up to the burst size.
+func (h *hostState) take(ctx context.Context) error {
+	if h.interval <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	now := time.Now()
+	if !h.last.IsZero() {
+		h.tokens += float64(now.Sub(h.last)) / float64(h.interval)
+	}
+	if h.tokens > h.burst {
+		h.tokens = h.burst
+	}
+	h.last = now
+	// Reserve the token now so concurrent callers queue behind each other
+	h.tokens--
+	var wait time.Duration
+	if h.tokens < 0 {
+		wait = time.Duration(-h.tokens * float64(h.interval))
+	}
+	h.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,155 @@
+package politeness
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots holds the robots.txt rules that apply to one user agent
+type Robots struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration // 0 when the file sets no Crawl-delay
+}
+
+// robotsRule is a single Allow or Disallow line
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// robotsGroup is a set of User-agent lines and the rules that follow them
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// ParseRobots parses a robots.txt file and keeps the group that best matches
+// agent: the group with the longest User-agent token contained in agent, or
+// the "*" group otherwise. Groups naming the same agent are merged.
+func ParseRobots(data []byte, agent string) *Robots {
+	var groups []*robotsGroup
+	var cur *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A User-agent line after rules starts a new group
+			if cur == nil || inRules {
+				cur = &robotsGroup{}
+				groups = append(groups, cur)
+				inRules = false
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			if cur == nil {
+				continue
+			}
+			inRules = true
+			// An empty Disallow allows everything and adds no rule
+			if value != "" {
+				cur.rules = append(cur.rules, robotsRule{allow: key == "allow", pattern: value})
+			}
+		case "crawl-delay":
+			if cur == nil {
+				continue
+			}
+			inRules = true
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				cur.crawlDelay = time.Duration(secs * float64(time.Second))
+			}
+		}
+	}
+
+	agent = strings.ToLower(agent)
+	best, bestLen := "", -1
+	for _, g := range groups {
+		for _, a := range g.agents {
+			switch {
+			case a == "*" && bestLen < 0:
+				best, bestLen = a, 0
+			case a != "*" && a != "" && strings.Contains(agent, a) && len(a) > bestLen:
+				best, bestLen = a, len(a)
+			}
+		}
+	}
+
+	r := &Robots{}
+	if bestLen < 0 {
+		return r
+	}
+	for _, g := range groups {
+		for _, a := range g.agents {
+			if a == best {
+				r.rules = append(r.rules, g.rules...)
+				if g.crawlDelay > r.CrawlDelay {
+					r.CrawlDelay = g.crawlDelay
+				}
+				break
+			}
+		}
+	}
+	return r
+}
+
+// Allowed reports whether path (including any query string) may be fetched.
+// The longest matching pattern wins; on a tie Allow wins.
+func (r *Robots) Allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	allowed, matchLen := true, -1
+	for _, rule := range r.rules {
+		if !matchPattern(rule.pattern, path) {
+			continue
+		}
+		n := len(rule.pattern)
+		if n > matchLen || (n == matchLen && rule.allow) {
+			allowed, matchLen = rule.allow, n
+		}
+	}
+	return allowed
+}
+
+// matchPattern matches a robots.txt path pattern, where "*" matches any
+// sequence of characters and a trailing "$" anchors the end of the path
+func matchPattern(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	for i, part := range parts[1:] {
+		// The last part of an anchored pattern must sit at the end of the path
+		if anchored && i == len(parts)-2 {
+			return len(path)-len(part) >= pos && strings.HasSuffix(path, part)
+		}
+		idx := strings.Index(path[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return !anchored || pos == len(path)
+}
@@ -0,0 +1,148 @@
+package politeness
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/", "/anything", true},
+		{"/private", "/private", true},
+		{"/private", "/private/page", true},
+		{"/private", "/public", false},
+		{"/*.php", "/index.php", true},
+		{"/*.php", "/dir/index.php?x=1", true},
+		{"/*.php", "/index.html", false},
+		{"/*.php$", "/index.php", true},
+		{"/*.php$", "/index.php?x=1", false},
+		{"/page$", "/page", true},
+		{"/page$", "/page/", false},
+		{"/a*b*c", "/a-b-c", true},
+		{"/a*b*c", "/a-c-b", false},
+		{"/*", "/", true},
+		{"/*ab$", "/ab", true},
+		{"/*ab$", "/abab", true},
+	}
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsAllowed(t *testing.T) {
+	const robots = `
+User-agent: *
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 3
+
+User-agent: otherbot
+Disallow: /
+`
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/", true},
+		{"/private", false},
+		{"/private/page", false},
+		{"/private/public", true},
+		{"/private/public/page", true},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+	}
+	r := ParseRobots([]byte(robots), Agent)
+	for _, tt := range tests {
+		if got := r.Allowed(tt.path); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if r.CrawlDelay != 3*time.Second {
+		t.Errorf("CrawlDelay = %v, want 3s", r.CrawlDelay)
+	}
+}
+
+func TestParseRobotsGroups(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		path   string
+		want   bool
+	}{
+		{
+			name:   "specific group wins over star",
+			robots: "User-agent: *\nDisallow: /\n\nUser-agent: durl\nDisallow: /admin\n",
+			path:   "/page",
+			want:   true,
+		},
+		{
+			name:   "agent token matched case-insensitively",
+			robots: "User-agent: DURL\nDisallow: /\n",
+			path:   "/page",
+			want:   false,
+		},
+		{
+			name:   "groups for the same agent are merged",
+			robots: "User-agent: durl\nDisallow: /a\n\nUser-agent: durl\nDisallow: /b\n",
+			path:   "/b",
+			want:   false,
+		},
+		{
+			name:   "consecutive user-agent lines share a group",
+			robots: "User-agent: otherbot\nUser-agent: durl\nDisallow: /x\n",
+			path:   "/x",
+			want:   false,
+		},
+		{
+			name:   "other agents do not apply",
+			robots: "User-agent: otherbot\nDisallow: /\n",
+			path:   "/page",
+			want:   true,
+		},
+		{
+			name:   "empty disallow allows everything",
+			robots: "User-agent: *\nDisallow:\n",
+			path:   "/page",
+			want:   true,
+		},
+		{
+			name:   "allow wins a tie",
+			robots: "User-agent: *\nDisallow: /page\nAllow: /page\n",
+			path:   "/page",
+			want:   true,
+		},
+		{
+			name:   "comments are ignored",
+			robots: "# comment\nUser-agent: * # everyone\nDisallow: /secret # hidden\n",
+			path:   "/secret",
+			want:   false,
+		},
+		{
+			name:   "rules before any user-agent are ignored",
+			robots: "Disallow: /\nUser-agent: *\nDisallow: /x\n",
+			path:   "/page",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ParseRobots([]byte(tt.robots), Agent)
+			if got := r.Allowed(tt.path); got != tt.want {
+				t.Errorf("Allowed(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilRobotsAllowsAll(t *testing.T) {
+	var r *Robots
+	if !r.Allowed("/anything") {
+		t.Error("nil Robots should allow every path")
+	}
+}
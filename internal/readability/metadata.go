@@ -0,0 +1,160 @@
+package readability
+
+import (
+	"encoding/json"
+	"strings"
+
+	"durl/internal/metadata"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Metadata describes the article as declared by the page
+type Metadata struct {
+	Title         string
+	Byline        string
+	Excerpt       string
+	SiteName      string
+	PublishedTime string
+}
+
+// titleSeparators split a document title into article and site parts
+var titleSeparators = []string{" | ", " - ", " – ", " — ", " :: ", " _ ", " » ", " / "}
+
+// articleMetadata derives the article metadata from what the page declares
+// (JSON-LD, OpenGraph, Twitter, Dublin Core and plain meta tags), falling
+// back to markup in doc such as <title>, rel=author and <time datetime>
+func articleMetadata(doc *goquery.Document, declared metadata.Metadata) Metadata {
+	meta := declared.Tags
+	ld := jsonLD(declared.JSONLD)
+
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v = normalizeSpace(v); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	m := Metadata{
+		Title: first(ld.title, meta["og:title"], meta["twitter:title"], meta["dc:title"], meta["dcterms:title"],
+			cleanTitle(doc.Find("title").First().Text())),
+		Byline: first(ld.byline, meta["author"], meta["article:author"], meta["dc:creator"], meta["dcterms:creator"],
+			meta["parsely-author"], doc.Find(`[rel="author"], [itemprop="author"] [itemprop="name"], [itemprop="author"], .byline, .author`).First().Text()),
+		Excerpt: first(ld.excerpt, meta["og:description"], meta["twitter:description"], meta["description"],
+			meta["dc:description"], meta["dcterms:description"]),
+		SiteName: first(ld.siteName, meta["og:site_name"], meta["application-name"]),
+		PublishedTime: first(ld.published, meta["article:published_time"], meta["datepublished"], meta["parsely-pub-date"],
+			meta["dc:date"], meta["dcterms:created"], doc.Find("time[datetime]").First().AttrOr("datetime", "")),
+	}
+	// A byline that is just a URL (e.g. article:author) is not useful
+	if strings.HasPrefix(m.Byline, "http://") || strings.HasPrefix(m.Byline, "https://") {
+		m.Byline = ""
+	}
+	return m
+}
+
+// cleanTitle strips the site name from a document title such as
+// "Article Title | Site", keeping the full title when the remainder is too short
+func cleanTitle(title string) string {
+	title = normalizeSpace(title)
+	cut := -1
+	for _, sep := range titleSeparators {
+		if i := strings.LastIndex(title, sep); i > cut {
+			cut = i
+		}
+	}
+	if cut <= 0 {
+		return title
+	}
+	if short := strings.TrimSpace(title[:cut]); len(strings.Fields(short)) >= 3 {
+		return short
+	}
+	return title
+}
+
+// ldArticle holds the fields read from JSON-LD
+type ldArticle struct {
+	title, byline, excerpt, siteName, published string
+}
+
+// jsonLD reads the first schema.org Article-like object from the page's
+// application/ld+json blocks
+func jsonLD(blocks []json.RawMessage) ldArticle {
+	var found ldArticle
+	for _, block := range blocks {
+		var data any
+		if err := json.Unmarshal(block, &data); err != nil {
+			continue
+		}
+		obj := findArticleObject(data)
+		if obj == nil {
+			continue
+		}
+		found.title = firstString(obj["headline"], obj["name"])
+		found.byline = names(obj["author"])
+		found.excerpt = firstString(obj["description"])
+		found.siteName = names(obj["publisher"])
+		found.published = firstString(obj["datePublished"], obj["dateCreated"])
+		break
+	}
+	return found
+}
+
+// findArticleObject searches a JSON-LD value (object, array or @graph) for
+// an object whose @type names an article
+func findArticleObject(v any) map[string]any {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if obj := findArticleObject(item); obj != nil {
+				return obj
+			}
+		}
+	case map[string]any:
+		types := []any{v["@type"]}
+		if list, ok := v["@type"].([]any); ok {
+			types = list
+		}
+		for _, t := range types {
+			s, _ := t.(string)
+			if strings.HasSuffix(s, "Article") || s == "BlogPosting" || s == "Report" {
+				return v
+			}
+		}
+		if graph, ok := v["@graph"]; ok {
+			return findArticleObject(graph)
+		}
+	}
+	return nil
+}
+
+// names joins the "name" of a JSON-LD person/organization value, which may
+// be a string, an object or a list of either
+func names(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		return firstString(v["name"])
+	case []any:
+		var out []string
+		for _, item := range v {
+			if n := names(item); n != "" {
+				out = append(out, n)
+			}
+		}
+		return strings.Join(out, ", ")
+	}
+	return ""
+}
+
+func firstString(values ...any) string {
+	for _, v := range values {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,411 @@
+// Package readability extracts the main article from an HTML document.
+// It is a port of the core of Mozilla's Readability.js: paragraphs are
+// scored by text length and comma count, scores propagate to their
+// ancestors, the best candidate and its related siblings form the article,
+// and the result is cleaned of link-heavy and boilerplate blocks.
+package readability
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"durl/internal/metadata"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// minTextLength is the article length below which extraction is retried
+// without stripping unlikely candidates
+const minTextLength = 500
+
+var (
+	unlikelyCandidates   = regexp.MustCompile(`(?i)-ad-|ai2html|banner|breadcrumbs|combx|comment|community|cover-wrap|disqus|extra|footer|gdpr|header|legends|menu|related|remark|replies|rss|shoutbox|sidebar|skyscraper|social|sponsor|supplemental|ad-break|agegate|pagination|pager|popup|yom-remote`)
+	okMaybeItsACandidate = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveWeight       = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|pagination|post|text|blog|story`)
+	negativeWeight       = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|gdpr|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+	unlikelyRoles        = map[string]bool{"menu": true, "menubar": true, "complementary": true, "navigation": true, "alert": true, "alertdialog": true, "dialog": true}
+	whitespace           = regexp.MustCompile(`\s+`)
+	// commas counts ASCII, Arabic, CJK full-width and other comma forms
+	commas = regexp.MustCompile(`[\x{002C}\x{060C}\x{FE50}\x{FE10}\x{FE11}\x{2E41}\x{2E34}\x{2E32}\x{FF0C}\x{3001}]`)
+)
+
+// blockChildren are the tags that stop a <div> from being scored as a paragraph
+var blockChildren = "a, blockquote, dl, div, img, ol, p, pre, table, ul, section, article, h1, h2, h3, h4, h5, h6"
+
+// Article is the extracted main content and its metadata
+type Article struct {
+	Metadata
+	Content     string // cleaned article HTML
+	TextContent string // article text with whitespace collapsed
+	Length      int    // TextContent length in characters
+}
+
+// Parse extracts the article from htmlContent. pageURL resolves relative
+// links and images in the result; it may be empty. declared is the page's
+// metadata, from which the article title, byline and dates are taken.
+func Parse(htmlContent, pageURL string, declared metadata.Metadata) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	meta := articleMetadata(doc, declared)
+
+	base, _ := url.Parse(pageURL)
+
+	// Like Readability.js, retry with unlikely candidates kept when the
+	// first pass finds too little text, and keep the longer result
+	var best *goquery.Selection
+	bestLen := -1
+	for _, strip := range []bool{true, false} {
+		// grabArticle modifies the document, so the retry parses it afresh
+		if !strip {
+			if doc, err = goquery.NewDocumentFromReader(strings.NewReader(htmlContent)); err != nil {
+				return nil, fmt.Errorf("failed to parse HTML: %w", err)
+			}
+		}
+		article := grabArticle(doc, strip)
+		n := textLength(article)
+		if n > bestLen {
+			best, bestLen = article, n
+		}
+		if n >= minTextLength {
+			break
+		}
+	}
+
+	removeTitleHeading(best, meta.Title)
+	fixURLs(best, base)
+
+	inner, err := best.Html()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render article: %w", err)
+	}
+	text := normalizeSpace(best.Text())
+	if meta.Excerpt == "" {
+		meta.Excerpt = normalizeSpace(best.Find("p").First().Text())
+	}
+
+	return &Article{
+		Metadata:    meta,
+		Content:     `<div id="readability-page-1" class="page">` + inner + `</div>`,
+		TextContent: text,
+		Length:      utf8.RuneCountInString(text),
+	}, nil
+}
+
+// grabArticle scores the document and returns a detached container
+// holding the top candidate and its related siblings
+func grabArticle(doc *goquery.Document, stripUnlikely bool) *goquery.Selection {
+	doc.Find("script, style, noscript, template, link, meta, iframe[hidden], object, embed").Remove()
+	doc.Find("[hidden], [aria-hidden=true]").Remove()
+	doc.Find("[style]").Each(func(_ int, s *goquery.Selection) {
+		style := strings.ReplaceAll(strings.ToLower(s.AttrOr("style", "")), " ", "")
+		if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+			s.Remove()
+		}
+	})
+
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		body = doc.Selection
+	}
+
+	if stripUnlikely {
+		body.Find("nav, aside, footer").Remove()
+		body.Find("*").Each(func(_ int, s *goquery.Selection) {
+			if unlikelyRoles[s.AttrOr("role", "")] {
+				s.Remove()
+				return
+			}
+			tag := goquery.NodeName(s)
+			if tag == "a" || tag == "body" || tag == "html" {
+				return
+			}
+			match := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+			if unlikelyCandidates.MatchString(match) && !okMaybeItsACandidate.MatchString(match) &&
+				s.Closest("table, code").Length() == 0 {
+				s.Remove()
+			}
+		})
+	}
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*goquery.Selection // in discovery order, so ties resolve deterministically
+	initialize := func(s *goquery.Selection) {
+		n := s.Get(0)
+		if _, ok := scores[n]; !ok {
+			scores[n] = tagWeight(goquery.NodeName(s)) + classWeight(s)
+			candidates = append(candidates, s)
+		}
+	}
+
+	body.Find("section, h2, h3, h4, h5, h6, p, td, pre, div").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "div" && s.Find(blockChildren).Length() > 0 {
+			return
+		}
+		text := normalizeSpace(s.Text())
+		n := utf8.RuneCountInString(text)
+		if n < 25 {
+			return
+		}
+
+		score := 1 + float64(len(commas.FindAllStringIndex(text, -1))) + math.Min(math.Floor(float64(n)/100), 3)
+
+		ancestors := s.Parents()
+		for level := 0; level < ancestors.Length() && level < 5; level++ {
+			a := ancestors.Eq(level)
+			if goquery.NodeName(a) == "html" {
+				break
+			}
+			initialize(a)
+			divider := 1.0
+			switch {
+			case level == 1:
+				divider = 2
+			case level > 1:
+				divider = float64(level * 3)
+			}
+			scores[a.Get(0)] += score / divider
+		}
+	})
+
+	// Scale by link density so navigation-heavy blocks lose out
+	var top *goquery.Selection
+	topScore := math.Inf(-1)
+	for _, s := range candidates {
+		n := s.Get(0)
+		score := scores[n] * (1 - linkDensity(s))
+		scores[n] = score
+		if score > topScore {
+			top, topScore = s, score
+		}
+	}
+
+	container := goquery.NewDocumentFromNode(&html.Node{Type: html.ElementNode, Data: "div"}).Selection
+	if top == nil || goquery.NodeName(top) == "body" {
+		body.Contents().Each(func(_ int, c *goquery.Selection) {
+			container.AppendSelection(c)
+		})
+		cleanArticle(container, scores)
+		return container
+	}
+
+	// Siblings that score well, share the top candidate's class or read
+	// like paragraphs are part of the article too
+	threshold := math.Max(10, topScore*0.2)
+	topClass := top.AttrOr("class", "")
+	parent := top.Parent()
+	siblings := top
+	if parent.Length() > 0 {
+		siblings = parent.Children()
+	}
+	siblings.Each(func(_ int, s *goquery.Selection) {
+		n := s.Get(0)
+		include := n == top.Get(0)
+		if !include {
+			bonus := 0.0
+			if topClass != "" && s.AttrOr("class", "") == topClass {
+				bonus = topScore * 0.2
+			}
+			if score, ok := scores[n]; ok && score+bonus >= threshold {
+				include = true
+			} else if goquery.NodeName(s) == "p" {
+				text := normalizeSpace(s.Text())
+				length := utf8.RuneCountInString(text)
+				density := linkDensity(s)
+				if length > 80 && density < 0.25 {
+					include = true
+				} else if last, _ := utf8.DecodeLastRuneInString(text); length > 0 && length <= 80 && density == 0 && strings.ContainsRune(".!?。！？", last) {
+					include = true
+				}
+			}
+		}
+		if include {
+			container.AppendSelection(s.Clone())
+		}
+	})
+
+	cleanArticle(container, scores)
+	return container
+}
+
+// cleanArticle removes boilerplate left inside the chosen content
+func cleanArticle(article *goquery.Selection, scores map[*html.Node]float64) {
+	article.Find("form, fieldset, button, input, select, textarea").Remove()
+
+	article.Find("h1, h2").Each(func(_ int, s *goquery.Selection) {
+		if classWeight(s) < 0 {
+			s.Remove()
+		}
+	})
+
+	// Conditional cleaning runs bottom-up so nested blocks are judged first
+	nodes := article.Find("table, ul, ol, div, section")
+	for i := nodes.Length() - 1; i >= 0; i-- {
+		s := nodes.Eq(i)
+		if s.Closest("pre, code").Length() > 0 || isDataTable(s) {
+			continue
+		}
+		if shouldRemove(s, scores) {
+			s.Remove()
+		}
+	}
+
+	// Empty paragraphs
+	article.Find("p").Each(func(_ int, s *goquery.Selection) {
+		if strings.TrimSpace(s.Text()) == "" && s.Find("img, picture, video, iframe, embed, object").Length() == 0 {
+			s.Remove()
+		}
+	})
+
+	article.Find("*").RemoveAttr("style")
+}
+
+// shouldRemove applies Readability's conditional cleaning heuristics
+func shouldRemove(s *goquery.Selection, scores map[*html.Node]float64) bool {
+	weight := classWeight(s)
+	if weight+scores[s.Get(0)] < 0 {
+		return true
+	}
+
+	text := normalizeSpace(s.Text())
+	if len(commas.FindAllStringIndex(text, -1)) >= 10 {
+		return false
+	}
+
+	tag := goquery.NodeName(s)
+	p := float64(s.Find("p").Length())
+	img := float64(s.Find("img").Length())
+	li := float64(s.Find("li").Length()) - 100
+	input := float64(s.Find("input").Length())
+	embeds := s.Find("embed, object, iframe").Length()
+	length := utf8.RuneCountInString(text)
+	density := linkDensity(s)
+	isList := tag == "ul" || tag == "ol"
+
+	switch {
+	case img > 1 && p/img < 0.5 && s.Closest("figure").Length() == 0:
+		return true
+	case !isList && li > p:
+		return true
+	case input > math.Floor(p/3):
+		return true
+	case !isList && length < 25 && (img == 0 || img > 2) && s.Find("h1, h2, h3, h4, h5, h6").Length() == 0:
+		return true
+	case !isList && weight < 25 && density > 0.2:
+		return true
+	case weight >= 25 && density > 0.5:
+		return true
+	case (embeds == 1 && length < 75) || embeds > 1:
+		return true
+	}
+	return false
+}
+
+// isDataTable reports whether a table holds data rather than layout
+func isDataTable(s *goquery.Selection) bool {
+	if goquery.NodeName(s) != "table" {
+		return false
+	}
+	if s.AttrOr("role", "") == "presentation" {
+		return false
+	}
+	if s.Find("caption, thead, th, colgroup").Length() > 0 || s.AttrOr("summary", "") != "" {
+		return true
+	}
+	rows := s.Find("tr").Length()
+	cols := s.Find("tr").First().Find("td").Length()
+	return rows >= 10 || cols > 4 || rows*cols > 10
+}
+
+// removeTitleHeading drops a leading heading that repeats the article title
+func removeTitleHeading(article *goquery.Selection, title string) {
+	if title == "" {
+		return
+	}
+	h := article.Find("h1, h2").First()
+	if h.Length() > 0 && normalizeSpace(h.Text()) == title {
+		h.Remove()
+	}
+}
+
+// fixURLs makes links and media sources absolute
+func fixURLs(article *goquery.Selection, base *url.URL) {
+	if base == nil || base.Host == "" {
+		return
+	}
+	for _, attr := range []string{"href", "src", "poster"} {
+		article.Find("[" + attr + "]").Each(func(_ int, s *goquery.Selection) {
+			v := strings.TrimSpace(s.AttrOr(attr, ""))
+			if v == "" || strings.HasPrefix(v, "#") || strings.HasPrefix(v, "data:") || strings.HasPrefix(strings.ToLower(v), "javascript:") {
+				return
+			}
+			if ref, err := url.Parse(v); err == nil {
+				s.SetAttr(attr, base.ResolveReference(ref).String())
+			}
+		})
+	}
+}
+
+// tagWeight is the initial score of a candidate by tag name
+func tagWeight(tag string) float64 {
+	switch tag {
+	case "div":
+		return 5
+	case "pre", "td", "blockquote":
+		return 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		return -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		return -5
+	}
+	return 0
+}
+
+// classWeight scores class and id names that hint at content or boilerplate
+func classWeight(s *goquery.Selection) float64 {
+	weight := 0.0
+	for _, name := range []string{s.AttrOr("class", ""), s.AttrOr("id", "")} {
+		if name == "" {
+			continue
+		}
+		if negativeWeight.MatchString(name) {
+			weight -= 25
+		}
+		if positiveWeight.MatchString(name) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of an element's text that sits inside links
+func linkDensity(s *goquery.Selection) float64 {
+	total := utf8.RuneCountInString(normalizeSpace(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	linked := 0.0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		// In-page anchors count less than outbound links
+		coefficient := 1.0
+		if strings.HasPrefix(a.AttrOr("href", ""), "#") {
+			coefficient = 0.3
+		}
+		linked += float64(utf8.RuneCountInString(normalizeSpace(a.Text()))) * coefficient
+	})
+	return linked / float64(total)
+}
+
+func textLength(s *goquery.Selection) int {
+	return utf8.RuneCountInString(normalizeSpace(s.Text()))
+}
+
+func normalizeSpace(s string) string {
+	return strings.TrimSpace(whitespace.ReplaceAllString(s, " "))
+}
@@ -0,0 +1,101 @@
+package readability
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"durl/internal/metadata"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseFixture(t *testing.T) {
+	data, err := os.ReadFile("testdata/article.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const pageURL = "https://news.example.com/2024/05/tidal/"
+	a, err := Parse(string(data), pageURL, metadata.Extract(string(data), pageURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Title != "Tidal Energy Comes of Age" {
+		t.Errorf("Title = %q", a.Title)
+	}
+	if a.Byline != "Morgan Lee" {
+		t.Errorf("Byline = %q", a.Byline)
+	}
+	if a.PublishedTime != "2024-05-02" {
+		t.Errorf("PublishedTime = %q", a.PublishedTime)
+	}
+	if a.SiteName != "Coastal Review" {
+		t.Errorf("SiteName = %q", a.SiteName)
+	}
+	if !strings.HasPrefix(a.Excerpt, "Off the northern coast") {
+		t.Errorf("Excerpt = %q", a.Excerpt)
+	}
+
+	for _, want := range []string{"Off the northern coast", "The consortium now plans a second phase"} {
+		if !strings.Contains(a.TextContent, want) {
+			t.Errorf("TextContent is missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"Most read", "Harbour dredging", "Great article", "All rights reserved", "About"} {
+		if strings.Contains(a.TextContent, unwanted) {
+			t.Errorf("TextContent keeps boilerplate %q", unwanted)
+		}
+	}
+	if strings.Contains(a.Content, "<h1>") {
+		t.Error("the heading repeating the title should be removed")
+	}
+	if !strings.Contains(a.Content, `src="https://news.example.com/2024/05/tidal/images/turbine.jpg"`) {
+		t.Errorf("relative image source not resolved: %s", a.Content)
+	}
+	if a.Length != len([]rune(a.TextContent)) {
+		t.Errorf("Length = %d, want %d", a.Length, len([]rune(a.TextContent)))
+	}
+}
+
+func TestScoringHelpers(t *testing.T) {
+	tests := []struct {
+		html        string
+		classWeight float64
+		linkDensity float64
+	}{
+		{`<div class="article-body">plain text only</div>`, 25, 0},
+		{`<div id="sidebar">text</div>`, -25, 0},
+		{`<div class="post-footer">text</div>`, 0, 0},
+		{`<div><a href="/x">abcd</a>abcd</div>`, 0, 0.5},
+		{`<div><a href="#x">abcd</a>abcd</div>`, 0, 0.15},
+		{`<div></div>`, 0, 0},
+	}
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := doc.Find("div").First()
+		if got := classWeight(s); got != tt.classWeight {
+			t.Errorf("classWeight(%s) = %v, want %v", tt.html, got, tt.classWeight)
+		}
+		if got := linkDensity(s); got != tt.linkDensity {
+			t.Errorf("linkDensity(%s) = %v, want %v", tt.html, got, tt.linkDensity)
+		}
+	}
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"Tidal Energy Comes of Age | Coastal Review", "Tidal Energy Comes of Age"},
+		{"Plain title", "Plain title"},
+	}
+	for _, tt := range tests {
+		if got := cleanTitle(tt.title); got != tt.want {
+			t.Errorf("cleanTitle(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package schema describes declarative structured extraction: named fields
+// with CSS/XPath selectors, list containers and value transforms, evaluated
+// against a rendered page into typed records.
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the records to extract from a page
+type Schema struct {
+	Name      string  `yaml:"name" json:"name,omitempty"`
+	Base      string  `yaml:"base" json:"base,omitempty"`             // CSS selector matching one element per record; empty = one record for the page
+	BaseXPath string  `yaml:"base_xpath" json:"base_xpath,omitempty"` // XPath alternative to Base
+	Fields    []Field `yaml:"fields" json:"fields"`
+
+	hash string // content hash of the schema file, for cache keys
+}
+
+// Field is one named value of a record
+type Field struct {
+	Name      string      `yaml:"name" json:"name"`
+	Selector  string      `yaml:"selector" json:"selector,omitempty"` // CSS selector relative to the record element
+	XPath     string      `yaml:"xpath" json:"xpath,omitempty"`       // XPath relative to the record element (e.g. ".//h2")
+	Type      string      `yaml:"type" json:"type,omitempty"`         // text (default), html, attr
+	Attr      string      `yaml:"attr" json:"attr,omitempty"`         // attribute name for type attr
+	Multiple  bool        `yaml:"multiple" json:"multiple,omitempty"` // keep all matches as a list
+	Transform []Transform `yaml:"transform" json:"-"`
+	Fields    []Field     `yaml:"fields" json:"fields,omitempty"` // nested record(s) for each match
+}
+
+// Load reads a YAML or JSON schema file (JSON is valid YAML)
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	var s Schema
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	s.hash = hex.EncodeToString(sum[:])
+	return &s, nil
+}
+
+// Hash identifies the schema contents
+func (s *Schema) Hash() string {
+	return s.hash
+}
+
+// FieldNames returns the top-level field names in schema order
+func (s *Schema) FieldNames() []string {
+	names := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// Script returns the page-side JavaScript that evaluates the schema and
+// returns the raw (untransformed) records; it takes one argument, whether
+// CSS selectors match inside open shadow roots
+func (s *Schema) Script() (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return fmt.Sprintf(extractScript, data), nil
+}
+
+func (s *Schema) validate() error {
+	if s.Base != "" && s.BaseXPath != "" {
+		return fmt.Errorf("base and base_xpath are mutually exclusive")
+	}
+	return validateFields(s.Fields, "")
+}
+
+func validateFields(fields []Field, parent string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("%sno fields defined", parent)
+	}
+	seen := map[string]bool{}
+	for _, f := range fields {
+		if f.Name == "" {
+			return fmt.Errorf("%sfield without a name", parent)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("%sduplicate field %q", parent, f.Name)
+		}
+		seen[f.Name] = true
+		if f.Selector != "" && f.XPath != "" {
+			return fmt.Errorf("field %q: selector and xpath are mutually exclusive", parent+f.Name)
+		}
+		switch f.Type {
+		case "", "text", "html":
+		case "attr":
+			if f.Attr == "" {
+				return fmt.Errorf("field %q: type attr requires attr", parent+f.Name)
+			}
+		default:
+			return fmt.Errorf("field %q: unknown type %q (text, html, attr)", parent+f.Name, f.Type)
+		}
+		if len(f.Fields) > 0 {
+			if len(f.Transform) > 0 {
+				return fmt.Errorf("field %q: nested fields cannot have transforms", parent+f.Name)
+			}
+			if err := validateFields(f.Fields, parent+f.Name+"."); err != nil {
+				return err
+			}
+		}
+		// Index into the shared slice so compiled regexes are kept
+		for i := range f.Transform {
+			if err := f.Transform[i].validate(); err != nil {
+				return fmt.Errorf("field %q: %w", parent+f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// extractScript is formatted with the JSON-encoded schema
+const extractScript = `(pierce) => {
+	const schema = %s;
+	const query = (root, sel) => {
+		const nodes = Array.from(root.querySelectorAll(sel));
+		if (pierce) [root, ...root.querySelectorAll('*')].forEach(e => { if (e.shadowRoot) nodes.push(...query(e.shadowRoot, sel)); });
+		return nodes;
+	};
+	const select = (root, sel, xpath) => {
+		if (xpath) {
+			const res = document.evaluate(xpath, root, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+			const nodes = [];
+			for (let i = 0; i < res.snapshotLength; i++) nodes.push(res.snapshotItem(i));
+			return nodes;
+		}
+		if (sel) return query(root, sel);
+		return [root];
+	};
+	const value = (node, f) => {
+		if (node.nodeType !== Node.ELEMENT_NODE) return node.textContent;
+		switch (f.type) {
+		case 'html': return node.innerHTML;
+		case 'attr': return node.getAttribute(f.attr);
+		default: return node.innerText !== undefined ? node.innerText : node.textContent;
+		}
+	};
+	const record = (root, fields) => {
+		const r = {};
+		for (const f of fields) {
+			const nodes = select(root, f.selector, f.xpath);
+			const vals = f.fields ? nodes.map(n => record(n, f.fields)) : nodes.map(n => value(n, f));
+			r[f.name] = f.multiple ? vals : (vals.length ? vals[0] : null);
+		}
+		return r;
+	};
+	const roots = (schema.base || schema.base_xpath)
+		? select(document, schema.base, schema.base_xpath)
+		: [document.documentElement];
+	return roots.map(root => record(root, schema.fields));
+}`
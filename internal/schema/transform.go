@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Transform is one value transform. In the schema it is written either as a
+// bare name ("trim", "number", "date", "absolute_url") or as a single-key
+// mapping with an argument ({regex: "..."}, {date: "2006-01-02"}).
+type Transform struct {
+	Name string
+	Arg  string
+
+	re *regexp.Regexp
+}
+
+var (
+	whitespace = regexp.MustCompile(`\s+`)
+	numberRe   = regexp.MustCompile(`[-+]?\d[\d,]*(?:\.\d+)?|[-+]?\.\d+`)
+)
+
+// dateLayouts are tried in order by the date transform when no layout is given
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	"2006.01.02",
+	"2006年01月02日 15:04",
+	"2006年1月2日 15:04",
+	"2006年01月02日",
+	"2006年1月2日",
+	time.RFC1123Z,
+	time.RFC1123,
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"01/02/2006",
+}
+
+// UnmarshalYAML accepts a scalar name or a single-key mapping
+func (t *Transform) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		t.Name = node.Value
+	case yaml.MappingNode:
+		if len(node.Content) != 2 {
+			return fmt.Errorf("line %d: a transform mapping must have exactly one key", node.Line)
+		}
+		t.Name = node.Content[0].Value
+		t.Arg = node.Content[1].Value
+	default:
+		return fmt.Errorf("line %d: invalid transform", node.Line)
+	}
+	return nil
+}
+
+func (t *Transform) validate() error {
+	switch t.Name {
+	case "trim", "number", "date", "absolute_url":
+	case "regex":
+		re, err := regexp.Compile(t.Arg)
+		if err != nil {
+			return fmt.Errorf("invalid regex transform: %w", err)
+		}
+		t.re = re
+	default:
+		return fmt.Errorf("unknown transform %q (trim, regex, number, date, absolute_url)", t.Name)
+	}
+	return nil
+}
+
+// apply transforms a single value; nil (no match or unparsable) propagates
+func (t *Transform) apply(v any, base *url.URL) any {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	switch t.Name {
+	case "trim":
+		return strings.TrimSpace(whitespace.ReplaceAllString(s, " "))
+	case "regex":
+		// The first capture group if the pattern has one, else the whole match
+		m := t.re.FindStringSubmatch(s)
+		switch {
+		case m == nil:
+			return nil
+		case len(m) > 1:
+			return m[1]
+		default:
+			return m[0]
+		}
+	case "number":
+		m := numberRe.FindString(s)
+		if m == "" {
+			return nil
+		}
+		n, err := strconv.ParseFloat(strings.ReplaceAll(m, ",", ""), 64)
+		if err != nil {
+			return nil
+		}
+		return n
+	case "date":
+		return parseDate(strings.TrimSpace(s), t.Arg)
+	case "absolute_url":
+		ref, err := url.Parse(strings.TrimSpace(s))
+		if err != nil || base == nil {
+			return s
+		}
+		return base.ResolveReference(ref).String()
+	}
+	return v
+}
+
+// parseDate returns the date as RFC 3339, or nil if it cannot be parsed
+func parseDate(s, layout string) any {
+	layouts := dateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t.Format(time.RFC3339)
+		}
+	}
+	return nil
+}
+
+// Apply runs each field's transforms over raw records returned by Script.
+// pageURL is the base for absolute_url.
+func (s *Schema) Apply(raw []map[string]any, pageURL string) []map[string]any {
+	base, _ := url.Parse(pageURL)
+	for _, r := range raw {
+		applyFields(r, s.Fields, base)
+	}
+	return raw
+}
+
+func applyFields(r map[string]any, fields []Field, base *url.URL) {
+	for _, f := range fields {
+		v, ok := r[f.Name]
+		if !ok || v == nil {
+			continue
+		}
+		if len(f.Fields) > 0 {
+			eachValue(v, f.Multiple, func(item any) any {
+				if nested, ok := item.(map[string]any); ok {
+					applyFields(nested, f.Fields, base)
+				}
+				return item
+			}, r, f.Name)
+			continue
+		}
+		eachValue(v, f.Multiple, func(item any) any {
+			for i := range f.Transform {
+				item = f.Transform[i].apply(item, base)
+			}
+			return item
+		}, r, f.Name)
+	}
+}
+
+// eachValue maps fn over a single value or a list and stores the result in r[name]
+func eachValue(v any, multiple bool, fn func(any) any, r map[string]any, name string) {
+	if list, ok := v.([]any); ok && multiple {
+		for i := range list {
+			list[i] = fn(list[i])
+		}
+		r[name] = list
+		return
+	}
+	r[name] = fn(v)
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTransformApply(t *testing.T) {
+	base, _ := url.Parse("https://example.com/shop/list?page=2")
+	march5 := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local).Format(time.RFC3339)
+	tests := []struct {
+		name string
+		tr   Transform
+		in   any
+		want any
+	}{
+		{"trim collapses whitespace", Transform{Name: "trim"}, "  a \n\t b  ", "a b"},
+		{"number with thousands separator", Transform{Name: "number"}, "Price: $1,234.50", 1234.5},
+		{"negative number", Transform{Name: "number"}, "change -3.2%", -3.2},
+		{"leading decimal point", Transform{Name: "number"}, ".75 kg", 0.75},
+		{"no number is nil", Transform{Name: "number"}, "n/a", nil},
+		{"date ISO", Transform{Name: "date"}, "2024-03-05", march5},
+		{"date Chinese", Transform{Name: "date"}, "2024年3月5日", march5},
+		{"date English", Transform{Name: "date"}, " March 5, 2024 ", march5},
+		{"date with layout", Transform{Name: "date", Arg: "02.01.2006"}, "05.03.2024", march5},
+		{"date layout does not fall back", Transform{Name: "date", Arg: "02.01.2006"}, "2024-03-05", nil},
+		{"unparsable date is nil", Transform{Name: "date"}, "yesterday", nil},
+		{"regex capture group", Transform{Name: "regex", Arg: `ID-(\d+)`}, "item ID-42 new", "42"},
+		{"regex whole match", Transform{Name: "regex", Arg: `\d+`}, "item 42", "42"},
+		{"regex no match is nil", Transform{Name: "regex", Arg: `\d+`}, "none", nil},
+		{"absolute_url relative path", Transform{Name: "absolute_url"}, "item/7", "https://example.com/shop/item/7"},
+		{"absolute_url root path", Transform{Name: "absolute_url"}, " /about ", "https://example.com/about"},
+		{"absolute_url keeps absolute", Transform{Name: "absolute_url"}, "https://other.com/x", "https://other.com/x"},
+		{"non-strings pass through", Transform{Name: "number"}, 3.0, 3.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.tr.validate(); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.tr.apply(tt.in, base); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformValidate(t *testing.T) {
+	tests := []struct {
+		tr      Transform
+		wantErr bool
+	}{
+		{Transform{Name: "trim"}, false},
+		{Transform{Name: "regex", Arg: `(\d+`}, true},
+		{Transform{Name: "upper"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.tr.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%+v) error = %v, wantErr %v", tt.tr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyChainsTransforms(t *testing.T) {
+	s := &Schema{Fields: []Field{
+		{Name: "price", Transform: []Transform{{Name: "trim"}, {Name: "number"}}},
+		{Name: "links", Multiple: true, Transform: []Transform{{Name: "absolute_url"}}},
+		{Name: "items", Multiple: true, Fields: []Field{
+			{Name: "qty", Transform: []Transform{{Name: "number"}}},
+		}},
+	}}
+	raw := []map[string]any{{
+		"price": " 12 ",
+		"links": []any{"/a", "b"},
+		"items": []any{map[string]any{"qty": "x3"}},
+	}}
+	want := []map[string]any{{
+		"price": 12.0,
+		"links": []any{"https://example.com/a", "https://example.com/dir/b"},
+		"items": []any{map[string]any{"qty": 3.0}},
+	}}
+	if got := s.Apply(raw, "https://example.com/dir/page"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %v, want %v", got, want)
+	}
+}
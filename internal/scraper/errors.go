@@ -0,0 +1,38 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSiteFailure wraps any error returned by a site-specific scraper
+	ErrSiteFailure = errors.New("failed to scrape")
+
+	// ErrSelectorNotFound is returned when a css/xpath selector matched nothing
+	ErrSelectorNotFound = errors.New("selector matched nothing")
+
+	// ErrBlocked is returned when the site served a captcha or anti-bot challenge
+	ErrBlocked = errors.New("blocked by anti-bot challenge")
+)
+
+// NetworkError reports a navigation that failed before a response arrived
+type NetworkError struct {
+	URL    string
+	Reason string // Chromium net error, e.g. net::ERR_NAME_NOT_RESOLVED
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error for %s: %s", e.URL, e.Reason)
+}
+
+// HTTPError reports a main document response with an error status (>= 400)
+type HTTPError struct {
+	URL        string
+	StatusCode int
+	StatusText string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("the requested URL returned error: %d %s", e.StatusCode, e.StatusText)
+}
@@ -3,11 +3,24 @@ package scraper
 import (
 	"context"
 	"time"
+
+	"durl/internal/actions"
+	"durl/internal/browser"
+	"durl/internal/cache"
+	"durl/internal/har"
+	"durl/internal/login"
+	"durl/internal/politeness"
+	"durl/internal/schema"
+
+	"github.com/go-rod/rod/lib/proto"
 )
 
 type Scraper interface {
 	Name() string
 	Scrape(ctx context.Context, target string, opts Options) (Content, error)
+	// RequestURL returns the URL Scrape fetches first for target, so
+	// politeness can rate-limit and robots-check queries that are not URLs
+	RequestURL(target string) string
 }
 
 type Content interface {
@@ -18,16 +31,88 @@ type Content interface {
 	ToCSV() (string, error)
 }
 
+// Snapshotter is implemented by Content that can be stored in the response cache
+type Snapshotter interface {
+	Snapshot() ([]byte, error)
+}
+
+// Capturer is implemented by Content holding a screenshot, PDF or archive of the page
+type Capturer interface {
+	Capture() []byte
+}
+
+// Restorer is implemented by scrapers that can rebuild their Content from a
+// cached Snapshot
+type Restorer interface {
+	Restore(data []byte) (Content, error)
+}
+
 type Options struct {
-	Method     string
-	Headers    map[string]string
-	Body       string
-	WaitFor    string
-	WaitTarget string
-	Timeout    time.Duration
-	Level      string // full/html/body/content/xpath/css
-	Selector   string
-	ShowUI     bool
-	ProxyURL   string            // --proxy flag or DURL_PROXY env var
-	Extra      map[string]string // Site-specific parameters (last-days/max-pages/sort, etc.)
+	Method      string
+	Headers     map[string]string
+	Body        string
+	WaitFor     string   // wait strategy, e.g. "load" or "element+networkidle"
+	WaitTargets []string // --wait-target values, one per strategy that needs one
+	Timeout     time.Duration
+	Level       string // full/html/body/content/xpath/css
+	Selector    string
+	ShowUI      bool
+	ProxyURL    string            // --proxy flag or DURL_PROXY env var
+	Extra       map[string]string // Site-specific parameters (last-days/max-pages/sort, etc.)
+	Pool        *browser.Pool     // Shared warm browsers; nil launches a dedicated browser per scrape
+	Fail        bool              // --fail: treat HTTP error status, empty selector matches and challenge pages as errors
+
+	Cookies     []*proto.NetworkCookieParam // -b/--cookie: cookies preloaded into every browser context
+	CookieJar   string                      // -c/--cookie-jar: file cookies are saved to after the scrape
+	UserDataDir string                      // --user-data-dir: persistent browser profile
+	Login       *login.Flow                 // --login: form login run before the fetch (generic mode)
+
+	Politeness *politeness.Policy  // robots.txt and per-host rate limit for batch and crawl; nil disables
+	Cache      *cache.Cache        // on-disk response cache; nil disables
+	HAR        *har.Recorder       // --har: network traffic of every page; nil disables
+	Block      *browser.BlockRules // --block: requests failed before they are sent; nil blocks nothing
+	Mock       *browser.MockRules  // --mock: mocked responses and header rewrites; nil disables
+
+	FrontMatter bool            // --front-matter: YAML front matter in generic markdown output
+	Schema      *schema.Schema  // --schema: structured records instead of level extraction (generic mode)
+	Actions     *actions.Script // --actions: page interactions run before extraction (generic mode)
+	Pagination  Pagination      // --paginate: harvest further pages of a list (generic mode)
+	Pierce      bool            // --pierce: CSS selectors and whole-page levels include open shadow roots
+	Frames      bool            // --frames: selectors search iframes and whole-page levels inline them
+	Attr        string          // --attr: output this attribute of each css/xpath match instead of its HTML
+	Text        bool            // --text: output the text of each css/xpath match instead of its HTML
+	Table       string          // --table: rows of the table with this 1-based index or caption, as records
+
+	Viewport browser.Viewport // --viewport/--device-scale: page size and device scale factor
+	Capture  Capture          // -f png/jpeg/pdf/mhtml/archive: capture taken while the page is alive (generic mode)
+}
+
+// Capture configures the screenshot, PDF or archive taken for -f png/jpeg/pdf/mhtml/archive
+type Capture struct {
+	Format    string // png, jpeg, pdf, mhtml or archive; empty disables capture
+	Paper     string // PDF paper size (a3, a4, a5, letter, legal, tabloid)
+	Landscape bool   // PDF orientation
+}
+
+// Pagination configures --paginate in generic mode
+type Pagination struct {
+	Mode     string // "scroll" or "next"; empty disables pagination
+	Next     string // CSS selector of the "next page" control (next mode)
+	Key      string // --dedupe-key: CSS selector, relative to each item, identifying repeated items
+	MaxPages int    // pages to harvest including the first; < 1 means until exhausted
+}
+
+// BrowserConfig builds the browser configuration described by the options
+func (o Options) BrowserConfig() browser.Config {
+	return browser.Config{
+		ProxyURL:    o.ProxyURL,
+		Headless:    !o.ShowUI,
+		UserDataDir: o.UserDataDir,
+		Cookies:     o.Cookies,
+		CookieJar:   o.CookieJar,
+		Viewport:    o.Viewport,
+		HAR:         o.HAR,
+		Block:       o.Block,
+		Mock:        o.Mock,
+	}
 }
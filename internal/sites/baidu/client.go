@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"durl/internal/browser"
+	"durl/internal/scraper"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
@@ -101,6 +102,13 @@ func (c *Client) Search(ctx context.Context, searchURL string, timeout time.Dura
 		return nil, fmt.Errorf("failed to parse results: %w", err)
 	}
 
+	// An empty result list is usually a captcha page rather than a real miss
+	if len(jsResults) == 0 {
+		if reason := browser.DetectChallenge(page); reason != "" {
+			return nil, fmt.Errorf("%w: %s", scraper.ErrBlocked, reason)
+		}
+	}
+
 	results := make([]Result, 0, len(jsResults))
 	for _, r := range jsResults {
 		snippet := strings.TrimSpace(multiNewline.ReplaceAllString(r.Snippet, "\n"))
@@ -2,10 +2,10 @@ package baidu
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 
-	"durl/internal/browser"
 	"durl/internal/scraper"
 )
 
@@ -18,17 +18,19 @@ type BaiduScraper struct{}
 
 func (s *BaiduScraper) Name() string { return "baidu" }
 
+// RequestURL returns the search results URL for query
+func (s *BaiduScraper) RequestURL(query string) string {
+	return "https://www.baidu.com/s?wd=" + url.QueryEscape(query)
+}
+
 func (s *BaiduScraper) Scrape(ctx context.Context, query string, opts scraper.Options) (scraper.Content, error) {
 	if query == "" {
 		return nil, fmt.Errorf("query is required for --site baidu")
 	}
 
-	searchURL := "https://www.baidu.com/s?wd=" + url.QueryEscape(query)
+	searchURL := s.RequestURL(query)
 
-	b, err := browser.New(browser.Config{
-		ProxyURL: opts.ProxyURL,
-		Headless: !opts.ShowUI,
-	})
+	b, err := opts.Pool.Acquire(opts.BrowserConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser: %w", err)
 	}
@@ -44,3 +46,12 @@ func (s *BaiduScraper) Scrape(ctx context.Context, query string, opts scraper.Op
 
 	return NewBaiduContent(query, searchURL, results), nil
 }
+
+// Restore rebuilds cached content
+func (s *BaiduScraper) Restore(data []byte) (scraper.Content, error) {
+	var snap searchSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode cached results: %w", err)
+	}
+	return NewBaiduContent(snap.Query, snap.SourceURL, snap.Results), nil
+}
@@ -79,3 +79,15 @@ func (c *BingContent) ToCSV() (string, error) {
 	w.Flush()
 	return buf.String(), nil
 }
+
+// searchSnapshot is the cached form of a BingContent
+type searchSnapshot struct {
+	Query     string   `json:"query"`
+	SourceURL string   `json:"source_url"`
+	Results   []Result `json:"results"`
+}
+
+// Snapshot encodes the results for the response cache
+func (c *BingContent) Snapshot() ([]byte, error) {
+	return json.Marshal(searchSnapshot{Query: c.query, SourceURL: c.sourceURL, Results: c.results})
+}
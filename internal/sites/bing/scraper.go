@@ -2,10 +2,10 @@ package bing
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 
-	"durl/internal/browser"
 	"durl/internal/scraper"
 )
 
@@ -18,17 +18,19 @@ type BingScraper struct{}
 
 func (s *BingScraper) Name() string { return "bing" }
 
+// RequestURL returns the search results URL for query
+func (s *BingScraper) RequestURL(query string) string {
+	return "https://cn.bing.com/search?q=" + url.QueryEscape(query) + "&PC=U316&FORM=CHROMN"
+}
+
 func (s *BingScraper) Scrape(ctx context.Context, query string, opts scraper.Options) (scraper.Content, error) {
 	if query == "" {
 		return nil, fmt.Errorf("query is required for --site bing")
 	}
 
-	searchURL := "https://cn.bing.com/search?q=" + url.QueryEscape(query) + "&PC=U316&FORM=CHROMN"
+	searchURL := s.RequestURL(query)
 
-	b, err := browser.New(browser.Config{
-		ProxyURL: opts.ProxyURL,
-		Headless: !opts.ShowUI,
-	})
+	b, err := opts.Pool.Acquire(opts.BrowserConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser: %w", err)
 	}
@@ -44,3 +46,12 @@ func (s *BingScraper) Scrape(ctx context.Context, query string, opts scraper.Opt
 
 	return NewBingContent(query, searchURL, results), nil
 }
+
+// Restore rebuilds cached content
+func (s *BingScraper) Restore(data []byte) (scraper.Content, error) {
+	var snap searchSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode cached results: %w", err)
+	}
+	return NewBingContent(snap.Query, snap.SourceURL, snap.Results), nil
+}
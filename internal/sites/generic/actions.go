@@ -0,0 +1,185 @@
+package generic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"durl/internal/actions"
+	"durl/internal/scraper"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// scrollPause is the default pause between repeated scrolls
+const scrollPause = 500 * time.Millisecond
+
+// CheckActions validates the wait steps of an actions script against the
+// wait strategies, so mistakes are reported before any page is fetched
+func CheckActions(s *actions.Script) error {
+	if s == nil {
+		return nil
+	}
+	for i, st := range s.Steps {
+		if st.Action != actions.Wait || st.For == "" {
+			continue
+		}
+		if _, err := ParseWait(st.For, st.Target); err != nil {
+			return fmt.Errorf("invalid actions: step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// runActions executes the steps of s in order; each step is bounded by timeout
+func (f *Fetcher) runActions(page *rod.Page, s *actions.Script, timeout time.Duration) error {
+	for i, st := range s.Steps {
+		fmt.Fprintf(os.Stderr, "[actions] %d/%d: %s\n", i+1, len(s.Steps), st)
+		if err := f.runAction(page.Timeout(timeout), st, timeout); err != nil {
+			if st.Optional {
+				fmt.Fprintf(os.Stderr, "[actions] skipped optional step %d: %v\n", i+1, err)
+				continue
+			}
+			return fmt.Errorf("action %d (%s) failed: %w", i+1, st, err)
+		}
+	}
+	return nil
+}
+
+func (f *Fetcher) runAction(page *rod.Page, st actions.Step, timeout time.Duration) error {
+	defer page.CancelTimeout()
+
+	switch st.Action {
+	case actions.Click:
+		el, err := page.Element(st.Selector)
+		if err != nil {
+			return err
+		}
+		return el.Click(proto.InputMouseButtonLeft, 1)
+
+	case actions.Type:
+		el, err := page.Element(st.Selector)
+		if err != nil {
+			return err
+		}
+		if st.Clear {
+			if err := el.SelectAllText(); err != nil {
+				return err
+			}
+		}
+		return el.Input(st.Text)
+
+	case actions.Press:
+		key, _ := actions.KeyFor(st.Key)
+		if st.Selector != "" {
+			el, err := page.Element(st.Selector)
+			if err != nil {
+				return err
+			}
+			if err := el.Focus(); err != nil {
+				return err
+			}
+		}
+		return page.Keyboard.Type(key)
+
+	case actions.Select:
+		el, err := page.Element(st.Selector)
+		if err != nil {
+			return err
+		}
+		// Match the option value first, then its visible text
+		byValue := fmt.Sprintf(`option[value=%q]`, st.Value)
+		if err := el.Select([]string{byValue}, true, rod.SelectorTypeCSSSector); err == nil {
+			return nil
+		}
+		return el.Select([]string{st.Value}, true, rod.SelectorTypeText)
+
+	case actions.Scroll:
+		return scroll(page, st)
+
+	case actions.Wait:
+		if st.For == "" {
+			return sleep(page, time.Duration(st.MS)*time.Millisecond)
+		}
+		steps, err := ParseWait(st.For, st.Target)
+		if err != nil {
+			return err
+		}
+		return f.applyWait(page, steps, timeout)
+
+	case actions.Eval:
+		// The script runs as a function body; its result is ignored
+		_, err := page.Eval("() => {\n" + st.Script + "\n}")
+		return err
+
+	case actions.Screenshot:
+		format := "png"
+		if ext := strings.ToLower(filepath.Ext(st.Path)); ext == ".jpg" || ext == ".jpeg" {
+			format = "jpeg"
+		}
+		level := ""
+		if st.Selector != "" {
+			level = "css"
+		}
+		data, err := capturePage(page, scraper.Capture{Format: format}, level, st.Selector, timeout)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(st.Path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write screenshot: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "[actions] screenshot saved to: %s\n", st.Path)
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", st.Action)
+}
+
+// scroll scrolls an element into view, or the window to the top, the bottom
+// or by a number of pixels (default one viewport), st.Repeat times
+func scroll(page *rod.Page, st actions.Step) error {
+	if st.Selector != "" {
+		el, err := page.Element(st.Selector)
+		if err != nil {
+			return err
+		}
+		return el.ScrollIntoView()
+	}
+
+	js := `y => window.scrollBy(0, y || window.innerHeight)`
+	switch st.To {
+	case "top":
+		js = `() => window.scrollTo(0, 0)`
+	case "bottom":
+		js = `() => window.scrollTo(0, document.documentElement.scrollHeight)`
+	}
+	pause := time.Duration(st.MS) * time.Millisecond
+	if pause == 0 {
+		pause = scrollPause
+	}
+	times := max(st.Repeat, 1)
+	for i := 0; i < times; i++ {
+		if i > 0 {
+			// Give lazy-loaded content time to arrive before scrolling again
+			if err := sleep(page, pause); err != nil {
+				return err
+			}
+		}
+		if _, err := page.Eval(js, st.Y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sleep pauses for d unless the page's timeout expires first
+func sleep(page *rod.Page, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-page.GetContext().Done():
+		return page.GetContext().Err()
+	}
+}
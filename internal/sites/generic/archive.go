@@ -0,0 +1,295 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// captureMHTML snapshots the page, its subresources and frames as MHTML
+func captureMHTML(page *rod.Page) ([]byte, error) {
+	res, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture MHTML snapshot: %w", err)
+	}
+	return []byte(res.Data), nil
+}
+
+// archivePart is one resource of an MHTML snapshot
+type archivePart struct {
+	contentType string
+	location    string
+	data        []byte
+}
+
+// mhtmlArchive indexes the parts of an MHTML snapshot by location and Content-ID
+type mhtmlArchive struct {
+	main  *archivePart
+	parts map[string]*archivePart
+}
+
+// cssURL matches url(...) references in stylesheets and style attributes
+var cssURL = regexp.MustCompile(`url\(\s*(?:"([^"]*)"|'([^']*)'|([^)'"\s]*))\s*\)`)
+
+// InlineMHTML turns an MHTML snapshot into a single HTML document with
+// stylesheets, images, fonts and frames inlined as data URIs. Scripts are
+// dropped so the archived DOM renders as captured.
+func InlineMHTML(data []byte) ([]byte, error) {
+	a, err := parseMHTML(data)
+	if err != nil {
+		return nil, err
+	}
+	out, err := a.inlineDocument(a.main, 0)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+func parseMHTML(data []byte) (*mhtmlArchive, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse MHTML: %w", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("failed to parse MHTML: not a multipart snapshot")
+	}
+
+	a := &mhtmlArchive{parts: map[string]*archivePart{}}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MHTML part: %w", err)
+		}
+		// quoted-printable is decoded by the multipart reader; base64 is not
+		var body io.Reader = p
+		if strings.EqualFold(p.Header.Get("Content-Transfer-Encoding"), "base64") {
+			body = base64.NewDecoder(base64.StdEncoding, p)
+		}
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MHTML part: %w", err)
+		}
+
+		part := &archivePart{
+			contentType: p.Header.Get("Content-Type"),
+			location:    p.Header.Get("Content-Location"),
+			data:        content,
+		}
+		if a.main == nil {
+			a.main = part
+		}
+		if part.location != "" {
+			a.parts[part.location] = part
+		}
+		if id := strings.Trim(p.Header.Get("Content-ID"), "<>"); id != "" {
+			a.parts["cid:"+id] = part
+		}
+	}
+	if a.main == nil {
+		return nil, fmt.Errorf("failed to parse MHTML: no parts")
+	}
+	return a, nil
+}
+
+// lookup finds the part for ref resolved against base
+func (a *mhtmlArchive) lookup(ref, base string) *archivePart {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") {
+		return nil
+	}
+	if p, ok := a.parts[ref]; ok {
+		return p
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+	return a.parts[b.ResolveReference(u).String()]
+}
+
+// dataURI inlines the resource ref, or returns ref unchanged when the
+// snapshot does not hold it
+func (a *mhtmlArchive) dataURI(ref, base string) string {
+	p := a.lookup(ref, base)
+	if p == nil {
+		return ref
+	}
+	data := p.data
+	if strings.HasPrefix(p.contentType, "text/css") {
+		data = []byte(a.inlineCSS(string(data), p.location))
+	}
+	ctype := p.contentType
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+	return "data:" + ctype + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// inlineCSS replaces url() references in css with data URIs
+func (a *mhtmlArchive) inlineCSS(css, base string) string {
+	return cssURL.ReplaceAllStringFunc(css, func(m string) string {
+		sub := cssURL.FindStringSubmatch(m)
+		ref := sub[1] + sub[2] + sub[3]
+		inlined := a.dataURI(ref, base)
+		if inlined == ref {
+			return m
+		}
+		return `url("` + inlined + `")`
+	})
+}
+
+// inlineDocument inlines the resources of an HTML part; frames are
+// embedded through srcdoc, up to a fixed nesting depth
+func (a *mhtmlArchive) inlineDocument(part *archivePart, depth int) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(part.data))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse archived HTML: %w", err)
+	}
+	base := part.location
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode && (c.DataAtom == atom.Script || c.DataAtom == atom.Noscript) {
+				n.RemoveChild(c)
+				c = next
+				continue
+			}
+			if c.Type == html.ElementNode && c.DataAtom == atom.Base {
+				if href := attr(c, "href"); href != "" {
+					if b, err := url.Parse(base); err == nil {
+						if u, err := url.Parse(href); err == nil {
+							base = b.ResolveReference(u).String()
+						}
+					}
+				}
+			}
+			a.inlineElement(n, c, base, depth)
+			walk(c)
+			c = next
+		}
+	}
+	walk(doc)
+
+	var buf bytes.Buffer
+	if err := html.Render(&buf, doc); err != nil {
+		return "", fmt.Errorf("failed to render archived HTML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// inlineElement rewrites the resource references of a single element
+func (a *mhtmlArchive) inlineElement(parent, n *html.Node, base string, depth int) {
+	if n.Type != html.ElementNode {
+		return
+	}
+	switch n.DataAtom {
+	case atom.Link:
+		rel := strings.ToLower(attr(n, "rel"))
+		if strings.Contains(rel, "stylesheet") {
+			if p := a.lookup(attr(n, "href"), base); p != nil {
+				// Replace the link with an equivalent <style>
+				style := &html.Node{Type: html.ElementNode, Data: "style", DataAtom: atom.Style}
+				if media := attr(n, "media"); media != "" {
+					style.Attr = []html.Attribute{{Key: "media", Val: media}}
+				}
+				style.AppendChild(&html.Node{Type: html.TextNode, Data: a.inlineCSS(string(p.data), p.location)})
+				parent.InsertBefore(style, n)
+				parent.RemoveChild(n)
+				return
+			}
+		}
+		if strings.Contains(rel, "icon") {
+			setAttr(n, "href", a.dataURI(attr(n, "href"), base))
+		}
+	case atom.Style:
+		if c := n.FirstChild; c != nil && c.Type == html.TextNode {
+			c.Data = a.inlineCSS(c.Data, base)
+		}
+	case atom.Iframe, atom.Frame:
+		if p := a.lookup(attr(n, "src"), base); p != nil && depth < 5 {
+			if inner, err := a.inlineDocument(p, depth+1); err == nil {
+				setAttr(n, "srcdoc", inner)
+				removeAttr(n, "src")
+			}
+		}
+	}
+
+	for i := range n.Attr {
+		switch n.Attr[i].Key {
+		case "src", "poster", "background":
+			if n.DataAtom != atom.Iframe && n.DataAtom != atom.Frame {
+				n.Attr[i].Val = a.dataURI(n.Attr[i].Val, base)
+			}
+		case "srcset":
+			n.Attr[i].Val = a.inlineSrcset(n.Attr[i].Val, base)
+		case "style":
+			n.Attr[i].Val = a.inlineCSS(n.Attr[i].Val, base)
+		}
+	}
+}
+
+// inlineSrcset inlines each candidate URL of a srcset attribute
+func (a *mhtmlArchive) inlineSrcset(srcset, base string) string {
+	candidates := strings.Split(srcset, ",")
+	for i, c := range candidates {
+		fields := strings.Fields(c)
+		if len(fields) == 0 {
+			continue
+		}
+		fields[0] = a.dataURI(fields[0], base)
+		candidates[i] = strings.Join(fields, " ")
+	}
+	return strings.Join(candidates, ", ")
+}
+
+func attr(n *html.Node, key string) string {
+	for _, at := range n.Attr {
+		if at.Key == key {
+			return at.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(n *html.Node, key, val string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr[i].Val = val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: val})
+}
+
+func removeAttr(n *html.Node, key string) {
+	for i := range n.Attr {
+		if n.Attr[i].Key == key {
+			n.Attr = append(n.Attr[:i], n.Attr[i+1:]...)
+			return
+		}
+	}
+}
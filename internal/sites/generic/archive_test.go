@@ -0,0 +1,145 @@
+package generic
+
+import (
+	"strings"
+	"testing"
+)
+
+// testSnapshot is a Chrome-style MHTML snapshot: a quoted-printable page,
+// a stylesheet, base64 images and a frame addressed by Content-ID
+var testSnapshot = strings.ReplaceAll(`From: <Saved by Blink>
+Snapshot-Content-Location: https://example.com/page/
+Subject: Test
+MIME-Version: 1.0
+Content-Type: multipart/related;
+	type="text/html";
+	boundary="----MultipartBoundary--abc----"
+
+------MultipartBoundary--abc----
+Content-Type: text/html
+Content-ID: <frame-main@mhtml.blink>
+Content-Transfer-Encoding: quoted-printable
+Content-Location: https://example.com/page/
+
+<html><head><link rel=3D"stylesheet" href=3D"style.css"><script>alert(1)</s=
+cript></head><body>
+<img src=3D"img/a.png" srcset=3D"img/a.png 1x, img/b.png 2x">
+<div style=3D"background: url('bg.gif')">x</div>
+<iframe src=3D"cid:frame-1@mhtml.blink"></iframe>
+<img src=3D"https://missing.example/x.png">
+</body></html>
+------MultipartBoundary--abc----
+Content-Type: text/css
+Content-Transfer-Encoding: quoted-printable
+Content-Location: https://example.com/page/style.css
+
+body { background: url(img/a.png); }
+------MultipartBoundary--abc----
+Content-Type: image/png
+Content-Transfer-Encoding: base64
+Content-Location: https://example.com/page/img/a.png
+
+UE5HMQ==
+------MultipartBoundary--abc----
+Content-Type: image/png
+Content-Transfer-Encoding: base64
+Content-Location: https://example.com/page/img/b.png
+
+UE5HMg==
+------MultipartBoundary--abc----
+Content-Type: image/gif
+Content-Transfer-Encoding: base64
+Content-Location: https://example.com/page/bg.gif
+
+R0lGMQ==
+------MultipartBoundary--abc----
+Content-Type: text/html
+Content-ID: <frame-1@mhtml.blink>
+Content-Transfer-Encoding: quoted-printable
+
+<html><body><p>framed</p><img src=3D"https://example.com/page/img/b.png"></=
+body></html>
+------MultipartBoundary--abc------
+`, "\n", "\r\n")
+
+const (
+	pngA = "data:image/png;base64,UE5HMQ=="
+	pngB = "data:image/png;base64,UE5HMg=="
+)
+
+func TestParseMHTML(t *testing.T) {
+	a, err := parseMHTML([]byte(testSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.main.location != "https://example.com/page/" || !strings.Contains(string(a.main.data), `<link rel="stylesheet"`) {
+		t.Errorf("main part = %q (%s), want the decoded page", a.main.location, a.main.data)
+	}
+	tests := []struct {
+		ref, base string
+		want      string // part data; empty for no part
+	}{
+		{"https://example.com/page/img/a.png", "", "PNG1"},
+		{"img/b.png", "https://example.com/page/", "PNG2"},
+		{"../page/bg.gif", "https://example.com/page/style.css", "GIF1"},
+		{"cid:frame-1@mhtml.blink", "", "<html><body><p>framed</p>"},
+		{"img/missing.png", "https://example.com/page/", ""},
+		{"#top", "https://example.com/page/", ""},
+		{"data:image/png;base64,AA==", "", ""},
+	}
+	for _, tt := range tests {
+		p := a.lookup(tt.ref, tt.base)
+		got := ""
+		if p != nil {
+			got = string(p.data)
+		}
+		if !strings.HasPrefix(got, tt.want) || (tt.want == "") != (got == "") {
+			t.Errorf("lookup(%q, %q) = %q, want %q", tt.ref, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestParseMHTMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not a message", ""},
+		{"not multipart", "Content-Type: text/html\r\n\r\n<p>x</p>"},
+		{"no parts", "Content-Type: multipart/related; boundary=\"b\"\r\n\r\n--b--\r\n"},
+	}
+	for _, tt := range tests {
+		if _, err := parseMHTML([]byte(tt.data)); err == nil {
+			t.Errorf("%s: want an error", tt.name)
+		}
+	}
+}
+
+func TestInlineMHTML(t *testing.T) {
+	out, err := InlineMHTML([]byte(testSnapshot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(out)
+	tests := []struct {
+		name    string
+		snippet string
+		want    bool
+	}{
+		{"scripts are dropped", "<script", false},
+		{"stylesheet link is replaced", "<link", false},
+		{"stylesheet becomes a style with inlined urls", `<style>body { background: url("` + pngA + `"); }</style>`, true},
+		{"img src is inlined", `src="` + pngA + `"`, true},
+		{"srcset candidates are inlined", `srcset="` + pngA + ` 1x, ` + pngB + ` 2x"`, true},
+		{"style attributes are inlined", `url(&#34;data:image/gif;base64,R0lGMQ==&#34;)`, true},
+		{"frames are embedded via srcdoc", `srcdoc="`, true},
+		{"frame content is inlined", `&lt;p&gt;framed&lt;/p&gt;`, true},
+		{"frame src is removed", `src="cid:`, false},
+		{"missing resources keep their URL", `src="https://missing.example/x.png"`, true},
+	}
+	for _, tt := range tests {
+		if got := strings.Contains(doc, tt.snippet); got != tt.want {
+			t.Errorf("%s: Contains(%q) = %v, want %v\n%s", tt.name, tt.snippet, got, tt.want, doc)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package generic
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"durl/internal/scraper"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// jpegQuality is used for -f jpeg screenshots
+const jpegQuality = 90
+
+// paperSizes are PDF paper sizes in inches (width, height, portrait)
+var paperSizes = map[string][2]float64{
+	"a3":      {11.69, 16.54},
+	"a4":      {8.27, 11.69},
+	"a5":      {5.83, 8.27},
+	"letter":  {8.5, 11},
+	"legal":   {8.5, 14},
+	"tabloid": {11, 17},
+}
+
+// ValidPaper reports whether name is a supported PDF paper size
+func ValidPaper(name string) bool {
+	_, ok := paperSizes[strings.ToLower(name)]
+	return ok
+}
+
+// capturePage takes the screenshot, PDF or archive described by c.
+// Screenshots are full-page, or clipped to the first match for the css and
+// xpath levels.
+func capturePage(page *rod.Page, c scraper.Capture, level, selector string, timeout time.Duration) ([]byte, error) {
+	switch c.Format {
+	case "png", "jpeg":
+		format := proto.PageCaptureScreenshotFormatPng
+		if c.Format == "jpeg" {
+			format = proto.PageCaptureScreenshotFormatJpeg
+		}
+		if level == "css" || level == "xpath" {
+			return captureElement(page, format, level, selector, timeout)
+		}
+		data, err := page.Timeout(timeout).Screenshot(true, screenshotRequest(format))
+		if err != nil {
+			return nil, fmt.Errorf("failed to take screenshot: %w", err)
+		}
+		return data, nil
+
+	case "pdf":
+		size := paperSizes[strings.ToLower(c.Paper)]
+		if size[0] == 0 {
+			size = paperSizes["a4"]
+		}
+		stream, err := page.Timeout(timeout).PDF(&proto.PagePrintToPDF{
+			Landscape:       c.Landscape,
+			PrintBackground: true,
+			PaperWidth:      &size[0],
+			PaperHeight:     &size[1],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to print PDF: %w", err)
+		}
+		data, err := io.ReadAll(stream)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read PDF: %w", err)
+		}
+		return data, nil
+
+	case "mhtml":
+		return captureMHTML(page.Timeout(timeout))
+
+	case "archive":
+		data, err := captureMHTML(page.Timeout(timeout))
+		if err != nil {
+			return nil, err
+		}
+		return InlineMHTML(data)
+	}
+	return nil, fmt.Errorf("unsupported capture format: %s", c.Format)
+}
+
+// captureElement screenshots the first element matching the selector
+func captureElement(page *rod.Page, format proto.PageCaptureScreenshotFormat, level, selector string, timeout time.Duration) ([]byte, error) {
+	var els rod.Elements
+	var err error
+	if level == "xpath" {
+		els, err = page.Timeout(timeout).ElementsX(selector)
+	} else {
+		els, err = page.Timeout(timeout).Elements(selector)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find element: %w", err)
+	}
+	if len(els) == 0 {
+		return nil, fmt.Errorf("%w: %s", scraper.ErrSelectorNotFound, selector)
+	}
+
+	// Element.Screenshot always sends a quality, which only applies to JPEG,
+	// and crops a device-pixel viewport capture by the CSS-pixel box, so the
+	// element is clipped by Chrome instead. The clip is in document
+	// coordinates so elements taller than the viewport are captured whole.
+	el := els[0].Timeout(timeout)
+	if err := el.ScrollIntoView(); err != nil {
+		return nil, fmt.Errorf("failed to take element screenshot: %w", err)
+	}
+	shape, err := el.Shape()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take element screenshot: %w", err)
+	}
+	box := shape.Box()
+	p := page.Timeout(timeout)
+	metrics, err := proto.PageGetLayoutMetrics{}.Call(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take element screenshot: %w", err)
+	}
+	req := screenshotRequest(format)
+	req.CaptureBeyondViewport = true
+	req.Clip = &proto.PageViewport{
+		X:      box.X + float64(metrics.CSSLayoutViewport.PageX),
+		Y:      box.Y + float64(metrics.CSSLayoutViewport.PageY),
+		Width:  box.Width,
+		Height: box.Height,
+		Scale:  1,
+	}
+	res, err := req.Call(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take element screenshot: %w", err)
+	}
+	return res.Data, nil
+}
+
+// screenshotRequest sets the JPEG quality only for JPEG screenshots
+func screenshotRequest(format proto.PageCaptureScreenshotFormat) *proto.PageCaptureScreenshot {
+	req := &proto.PageCaptureScreenshot{Format: format}
+	if format == proto.PageCaptureScreenshotFormatJpeg {
+		q := jpegQuality
+		req.Quality = &q
+	}
+	return req
+}
+
+// SetCapture attaches a screenshot, PDF or archive to the content
+func (p *pageInfo) SetCapture(format string, data []byte) {
+	p.captureFormat = format
+	p.capture = data
+}
+
+// Capture returns the screenshot, PDF or archive, or nil when none was taken
+func (p *pageInfo) Capture() []byte {
+	return p.capture
+}
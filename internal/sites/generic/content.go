@@ -5,42 +5,123 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+
+	"durl/internal/metadata"
+	"durl/internal/readability"
+	"durl/internal/scraper"
+	"durl/internal/table"
 
 	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/PuerkitoBio/goquery"
 )
 
+// Content is the result of a generic scrape: a PageContent, or a
+// RecordsContent, InventoryContent or ValuesContent when --schema/--table,
+// --level links or --attr/--text replace level extraction
+type Content interface {
+	scraper.Content
+	scraper.Snapshotter
+	scraper.Capturer
+	Response() Response
+	Metadata() metadata.Metadata
+	Summary() string
+	SetFrontMatter(on bool)
+	SetCapture(format string, data []byte)
+	info() *pageInfo
+}
+
+// pageInfo holds what every kind of content knows about the fetched page
+type pageInfo struct {
+	title       string
+	url         string
+	loadTime    time.Duration
+	response    Response             // main document status, headers and redirect chain
+	article     readability.Metadata // byline, excerpt, site name and published time (content level)
+	meta        metadata.Metadata    // description, canonical, language, OpenGraph, Twitter, JSON-LD, microdata
+	frontMatter bool                 // prepend YAML front matter in ToMarkdown
+
+	captureFormat string // -f format of the capture, when one was taken
+	capture       []byte // screenshot, PDF or archive of the page
+}
+
 // PageContent holds pre-extracted page content as strings.
 // All content is extracted while the browser is still open (in Scrape),
 // so the formatters do not need a live browser connection.
 type PageContent struct {
+	pageInfo
 	htmlContent string // for ToHTML(): body innerHTML (or selector HTML)
 	mainContent string // for ToMarkdown(), ToCSV(), ToJSON(): content at the requested level/selector
 	textContent string // for ToText(): plain text (body innerText when level=body, else same as mainContent)
 	level       string // original level flag, used to decide ToText conversion
-	title       string
-	url         string
-	loadTime    time.Duration
 }
 
-// NewPageContent creates a PageContent from pre-extracted strings.
-// htmlContent: HTML for ToHTML()
-// mainContent: HTML (or text) for ToMarkdown/ToCSV/ToJSON
-// textContent: plain text for ToText() when level=="body", else HTML to be converted
-// level: the original --level flag value
-func NewPageContent(htmlContent, mainContent, textContent, level, title, url string, loadTime time.Duration) *PageContent {
+// PageOptions are the pre-extracted strings and page facts of a PageContent
+type PageOptions struct {
+	HTML     string               // for ToHTML(): body innerHTML (or selector HTML)
+	Main     string               // for ToMarkdown(), ToCSV(), ToJSON(): content at the requested level/selector
+	Text     string               // for ToText(): plain text when Level is "body", else HTML to be converted
+	Level    string               // original --level flag value
+	Title    string               // document title
+	URL      string               // final URL after redirects
+	LoadTime time.Duration        // page load time
+	Response Response             // main document response captured during the fetch
+	Article  readability.Metadata // Readability metadata (zero unless Level is "content")
+	Meta     metadata.Metadata    // document metadata
+}
+
+// NewPageContent creates a PageContent from pre-extracted strings
+func NewPageContent(o PageOptions) *PageContent {
 	return &PageContent{
-		htmlContent: htmlContent,
-		mainContent: mainContent,
-		textContent: textContent,
-		level:       level,
-		title:       title,
-		url:         url,
-		loadTime:    loadTime,
+		pageInfo: pageInfo{
+			title:    o.Title,
+			url:      o.URL,
+			loadTime: o.LoadTime,
+			response: o.Response,
+			article:  o.Article,
+			meta:     o.Meta,
+		},
+		htmlContent: o.HTML,
+		mainContent: o.Main,
+		textContent: o.Text,
+		level:       o.Level,
+	}
+}
+
+// Response returns the main document response captured during the fetch
+func (p *pageInfo) Response() Response {
+	return p.response
+}
+
+// Article returns the Readability metadata (content level only)
+func (p *pageInfo) Article() readability.Metadata {
+	return p.article
+}
+
+// Metadata returns the document metadata
+func (p *pageInfo) Metadata() metadata.Metadata {
+	return p.meta
+}
+
+// info returns the shared page fields
+func (p *pageInfo) info() *pageInfo {
+	return p
+}
+
+// SetFrontMatter enables YAML front matter at the top of ToMarkdown output
+func (p *pageInfo) SetFrontMatter(on bool) {
+	p.frontMatter = on
+}
+
+// withFrontMatter prepends the front matter to markdown when enabled
+func (p *pageInfo) withFrontMatter(markdown string) string {
+	if !p.frontMatter {
+		return markdown
 	}
+	return p.frontMatterYAML() + "\n" + markdown
 }
 
 // ToHTML returns HTML format content
@@ -65,18 +146,97 @@ func (p *PageContent) ToText() (string, error) {
 
 // ToMarkdown returns Markdown format content
 func (p *PageContent) ToMarkdown() (string, error) {
-	htmlWithMarkdownTables := convertTablesInHTML(p.mainContent)
+	htmlWithPlaceholders, tables := convertTablesInHTML(p.mainContent)
 
 	converter := md.NewConverter("", true, nil)
-	markdown, err := converter.ConvertString(htmlWithMarkdownTables)
+	markdown, err := converter.ConvertString(htmlWithPlaceholders)
 	if err != nil {
 		return "", fmt.Errorf("failed to convert HTML to Markdown: %w", err)
 	}
 
-	markdown = strings.ReplaceAll(markdown, "<!-- MARKDOWN_TABLE -->", "")
-	markdown = strings.ReplaceAll(markdown, "<!-- END_MARKDOWN_TABLE -->", "")
+	// Tables are substituted after conversion so the converter does not
+	// escape their pipes
+	for i, t := range tables {
+		markdown = strings.Replace(markdown, tablePlaceholder(i), strings.TrimSuffix(t, "\n"), 1)
+	}
 
-	return markdown, nil
+	return p.withFrontMatter(markdown), nil
+}
+
+// summaryFields returns the key page facts shared by the front matter and
+// the stderr summary, in display order; empty values are omitted
+func (p *pageInfo) summaryFields() [][2]string {
+	og := p.meta.OpenGraph
+	first := func(values ...string) string {
+		for _, v := range values {
+			if v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	fields := [][2]string{
+		{"title", p.title},
+		{"url", p.url},
+		{"canonical", p.meta.Canonical},
+		{"description", first(p.meta.Description, og["og:description"], p.article.Excerpt)},
+		{"language", p.meta.Language},
+		{"author", p.article.Byline},
+		{"site_name", first(p.article.SiteName, og["og:site_name"])},
+		{"published_time", first(p.article.PublishedTime, og["article:published_time"])},
+		{"image", first(og["og:image"], p.meta.Twitter["twitter:image"])},
+	}
+	out := fields[:0]
+	for _, f := range fields {
+		if f[1] != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// frontMatterYAML renders summaryFields as a YAML front matter block
+func (p *pageInfo) frontMatterYAML() string {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, f := range p.summaryFields() {
+		// Go's quoted strings are valid YAML double-quoted scalars
+		sb.WriteString(fmt.Sprintf("%s: %s\n", f[0], strconv.Quote(f[1])))
+	}
+	if p.response.StatusCode != 0 {
+		sb.WriteString(fmt.Sprintf("status: %d\n", p.response.StatusCode))
+	}
+	sb.WriteString("---\n")
+	return sb.String()
+}
+
+// Summary returns a short human-readable metadata summary for stderr
+func (p *pageInfo) Summary() string {
+	labels := map[string]string{
+		"title": "Title", "url": "URL", "canonical": "Canonical", "description": "Description",
+		"language": "Language", "author": "Author", "site_name": "Site", "published_time": "Published", "image": "Image",
+	}
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	for _, f := range p.summaryFields() {
+		value := f[1]
+		if f[0] == "description" && utf8.RuneCountInString(value) > 160 {
+			value = string([]rune(value)[:157]) + "..."
+		}
+		sb.WriteString(fmt.Sprintf("%-12s %s\n", labels[f[0]]+":", value))
+	}
+	if p.response.StatusCode != 0 {
+		sb.WriteString(fmt.Sprintf("%-12s %d %s\n", "Status:", p.response.StatusCode, p.response.StatusText))
+	}
+	sb.WriteString(fmt.Sprintf("%-12s %s\n", "Load time:", p.loadTime.Round(time.Millisecond)))
+	if n := len(p.meta.JSONLD); n > 0 {
+		sb.WriteString(fmt.Sprintf("%-12s %d block(s)\n", "JSON-LD:", n))
+	}
+	if n := len(p.meta.Microdata); n > 0 {
+		sb.WriteString(fmt.Sprintf("%-12s %d item(s)\n", "Microdata:", n))
+	}
+	return sb.String()
 }
 
 // ToJSON returns JSON format content
@@ -97,21 +257,46 @@ func (p *PageContent) ToJSON() ([]byte, error) {
 	}
 
 	type jsonOutput struct {
-		HTML     string `json:"html"`
-		Text     string `json:"text"`
-		Markdown string `json:"markdown"`
-		Title    string `json:"title"`
-		URL      string `json:"url"`
-		LoadTime int64  `json:"load_time"`
+		HTML        string            `json:"html"`
+		Text        string            `json:"text"`
+		Markdown    string            `json:"markdown"`
+		Title       string            `json:"title"`
+		URL         string            `json:"url"`
+		LoadTime    int64             `json:"load_time"`
+		Status      int               `json:"status"`
+		StatusText  string            `json:"status_text"`
+		ContentType string            `json:"content_type"`
+		Headers     map[string]string `json:"headers"`
+		Redirects   []Redirect        `json:"redirects"`
+
+		// Readability metadata, set for the content level
+		Byline        string `json:"byline,omitempty"`
+		Excerpt       string `json:"excerpt,omitempty"`
+		SiteName      string `json:"site_name,omitempty"`
+		PublishedTime string `json:"published_time,omitempty"`
+
+		Metadata metadata.Metadata `json:"metadata"`
 	}
 
 	output := jsonOutput{
-		HTML:     html,
-		Text:     text,
-		Markdown: markdown,
-		Title:    p.title,
-		URL:      p.url,
-		LoadTime: p.loadTime.Milliseconds(),
+		HTML:        html,
+		Text:        text,
+		Markdown:    markdown,
+		Title:       p.title,
+		URL:         p.url,
+		LoadTime:    p.loadTime.Milliseconds(),
+		Status:      p.response.StatusCode,
+		StatusText:  p.response.StatusText,
+		ContentType: p.response.ContentType,
+		Headers:     p.response.Headers,
+		Redirects:   p.response.Redirects,
+
+		Byline:        p.article.Byline,
+		Excerpt:       p.article.Excerpt,
+		SiteName:      p.article.SiteName,
+		PublishedTime: p.article.PublishedTime,
+
+		Metadata: p.meta,
 	}
 
 	return json.MarshalIndent(output, "", "  ")
@@ -119,109 +304,187 @@ func (p *PageContent) ToJSON() ([]byte, error) {
 
 // ToCSV returns CSV format content (extracts all HTML tables from page)
 func (p *PageContent) ToCSV() (string, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(p.mainContent))
+	tables, err := table.ParseAll(p.mainContent)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+		return "", err
 	}
 
 	var buf bytes.Buffer
-	tableIndex := 0
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		tableIndex++
-		if tableIndex > 1 {
+	for i, t := range tables {
+		if i > 0 {
 			buf.WriteString("\n")
 		}
-		buf.WriteString(fmt.Sprintf("# Table %d\n", tableIndex))
-
-		w := csv.NewWriter(&buf)
-		table.Find("tr").Each(func(j int, row *goquery.Selection) {
-			var record []string
-			row.Find("th, td").Each(func(k int, cell *goquery.Selection) {
-				record = append(record, strings.TrimSpace(cell.Text()))
-			})
-			if len(record) > 0 {
-				_ = w.Write(record)
-			}
-		})
-		w.Flush()
-	})
+		buf.WriteString(fmt.Sprintf("# Table %d", i+1))
+		if t.Caption != "" {
+			buf.WriteString(": " + t.Caption)
+		}
+		buf.WriteString("\n")
+		if err := t.WriteCSV(csv.NewWriter(&buf)); err != nil {
+			return "", fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
 
 	return buf.String(), nil
 }
 
-// convertTablesInHTML converts all tables in HTML to Markdown table format
-func convertTablesInHTML(htmlContent string) string {
-	re := regexp.MustCompile(`(?is)<table\b[^>]*>.*?</table>`)
-	return re.ReplaceAllStringFunc(htmlContent, convertHTMLTableToMarkdown)
-}
+// convertTablesInHTML replaces each top-level table in HTML with a
+// placeholder paragraph and returns the tables converted to Markdown, in
+// order; nested tables follow their parent's Markdown
+func convertTablesInHTML(htmlContent string) (string, []string) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return htmlContent, nil
+	}
+	top := doc.Find("table").FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.ParentsFiltered("table").Length() == 0
+	})
+	if top.Length() == 0 {
+		return htmlContent, nil
+	}
 
-// convertHTMLTableToMarkdown converts HTML table to Markdown table
-func convertHTMLTableToMarkdown(tableHTML string) string {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(tableHTML))
+	var tables []string
+	top.Each(func(_ int, s *goquery.Selection) {
+		tables = append(tables, tableMarkdown(s))
+		s.ReplaceWithHtml("<p>" + tablePlaceholder(len(tables)-1) + "</p>")
+	})
+	out, err := doc.Html()
 	if err != nil {
-		return tableHTML
+		return htmlContent, nil
 	}
+	return out, tables
+}
 
-	var builder strings.Builder
-	doc.Find("table").Each(func(i int, table *goquery.Selection) {
-		headers := []string{}
-		headerRow := table.Find("thead tr").First()
-		if headerRow.Length() == 0 {
-			headerRow = table.Find("tr").First()
-		}
-		headerRow.Find("th, td").Each(func(j int, header *goquery.Selection) {
-			text := strings.TrimSpace(header.Text())
-			headers = append(headers, text)
-		})
+// tablePlaceholder marks where table i goes; letters and digits only, so
+// Markdown conversion leaves it intact
+func tablePlaceholder(i int) string {
+	return fmt.Sprintf("DURLTABLE%dPLACEHOLDER", i)
+}
 
-		if len(headers) < 1 {
+// tableMarkdown converts a table and the tables nested in it to Markdown
+func tableMarkdown(s *goquery.Selection) string {
+	var builder strings.Builder
+	s.AddSelection(s.Find("table")).Each(func(_ int, t *goquery.Selection) {
+		parsed := table.Parse(t)
+		if parsed == nil {
 			return
 		}
-
-		builder.WriteString("| ")
-		for j, hdr := range headers {
-			if j > 0 {
-				builder.WriteString(" | ")
-			}
-			builder.WriteString(strings.TrimSpace(hdr))
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
 		}
-		builder.WriteString(" |\n")
+		builder.WriteString(parsed.Markdown())
+	})
+	return builder.String()
+}
 
-		builder.WriteString("| ")
-		for j := range headers {
-			if j > 0 {
-				builder.WriteString(" | ")
-			}
-			builder.WriteString("---")
-		}
-		builder.WriteString(" |\n")
+// Snapshot kinds, one per Content type
+const (
+	kindPage      = "page"
+	kindRecords   = "records"
+	kindInventory = "inventory"
+	kindValues    = "values"
+)
 
-		dataRows := table.Find("tbody tr")
-		if dataRows.Length() == 0 {
-			dataRows = table.Find("tr").Slice(1, goquery.ToEnd)
-		}
+// snapshot is the cached form of a Content
+type snapshot struct {
+	Kind        string            `json:"kind"`
+	HTMLContent string            `json:"html_content,omitempty"`
+	MainContent string            `json:"main_content,omitempty"`
+	TextContent string            `json:"text_content,omitempty"`
+	Level       string            `json:"level,omitempty"`
+	Title       string            `json:"title"`
+	URL         string            `json:"url"`
+	LoadTime    time.Duration     `json:"load_time"`
+	Response    Response          `json:"response"`
+	Article     articleMeta       `json:"article"`
+	Metadata    metadata.Metadata `json:"metadata"`
+
+	RecordFields []string         `json:"record_fields,omitempty"`
+	Records      []map[string]any `json:"records,omitempty"`
+	Inventory    *Inventory       `json:"inventory,omitempty"`
+	Values       []string         `json:"values,omitempty"`
+
+	CaptureFormat string `json:"capture_format,omitempty"`
+	Capture       []byte `json:"capture,omitempty"`
+}
 
-		dataRows.Each(func(j int, row *goquery.Selection) {
-			cells := []string{}
-			row.Find("td, th").Each(func(k int, cell *goquery.Selection) {
-				text := strings.TrimSpace(cell.Text())
-				cells = append(cells, text)
-			})
-
-			if len(cells) >= 1 {
-				builder.WriteString("| ")
-				for k, cell := range cells {
-					if k > 0 {
-						builder.WriteString(" | ")
-					}
-					builder.WriteString(strings.TrimSpace(cell))
-				}
-				builder.WriteString(" |\n")
-			}
-		})
+// articleMeta is the cached form of readability.Metadata
+type articleMeta struct {
+	Title         string `json:"title,omitempty"`
+	Byline        string `json:"byline,omitempty"`
+	Excerpt       string `json:"excerpt,omitempty"`
+	SiteName      string `json:"site_name,omitempty"`
+	PublishedTime string `json:"published_time,omitempty"`
+}
 
-		builder.WriteString("\n")
-	})
+// snapshot returns the cached form of the shared page fields
+func (p *pageInfo) snapshot(kind string) snapshot {
+	return snapshot{
+		Kind:          kind,
+		Title:         p.title,
+		URL:           p.url,
+		LoadTime:      p.loadTime,
+		Response:      p.response,
+		Article:       articleMeta(p.article),
+		Metadata:      p.meta,
+		CaptureFormat: p.captureFormat,
+		Capture:       p.capture,
+	}
+}
 
-	return builder.String()
+// pageInfo rebuilds the shared page fields from a snapshot
+func (s snapshot) pageInfo() pageInfo {
+	return pageInfo{
+		title:         s.Title,
+		url:           s.URL,
+		loadTime:      s.LoadTime,
+		response:      s.Response,
+		article:       readability.Metadata(s.Article),
+		meta:          s.Metadata,
+		captureFormat: s.CaptureFormat,
+		capture:       s.Capture,
+	}
+}
+
+// Snapshot encodes the extracted content for the response cache
+func (p *PageContent) Snapshot() ([]byte, error) {
+	s := p.snapshot(kindPage)
+	s.HTMLContent = p.htmlContent
+	s.MainContent = p.mainContent
+	s.TextContent = p.textContent
+	s.Level = p.level
+	return json.Marshal(s)
+}
+
+// RestoreContent rebuilds a Content from a Snapshot
+func RestoreContent(data []byte) (Content, error) {
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode cached page: %w", err)
+	}
+	switch s.Kind {
+	case kindPage:
+		return &PageContent{
+			pageInfo:    s.pageInfo(),
+			htmlContent: s.HTMLContent,
+			mainContent: s.MainContent,
+			textContent: s.TextContent,
+			level:       s.Level,
+		}, nil
+	case kindRecords:
+		if s.Records == nil {
+			s.Records = []map[string]any{}
+		}
+		return &RecordsContent{pageInfo: s.pageInfo(), fields: s.RecordFields, records: s.Records}, nil
+	case kindInventory:
+		if s.Inventory == nil {
+			return nil, fmt.Errorf("failed to decode cached page: missing inventory")
+		}
+		return &InventoryContent{pageInfo: s.pageInfo(), inventory: s.Inventory}, nil
+	case kindValues:
+		if s.Values == nil {
+			s.Values = []string{}
+		}
+		return &ValuesContent{pageInfo: s.pageInfo(), values: s.Values}, nil
+	}
+	return nil, fmt.Errorf("failed to decode cached page: unknown kind %q", s.Kind)
 }
@@ -5,12 +5,24 @@ import (
 	"strings"
 	"time"
 
+	"durl/internal/metadata"
+	"durl/internal/readability"
+	"durl/internal/schema"
+
 	"github.com/go-rod/rod"
 )
 
 // Extractor content extractor
 type Extractor struct {
-	page *rod.Page
+	page    *rod.Page
+	article *readability.Article // set when the content level used Readability
+
+	pierce  bool // CSS selectors match inside open shadow roots (--pierce)
+	frames  bool // iframes are searched and inlined (--frames)
+	inlined bool // frame and shadow content has been inlined into the document
+
+	doc  string            // rendered HTML already fetched by the caller, until the document changes
+	meta metadata.Metadata // document metadata, handed to Readability
 }
 
 // NewExtractor creates a new Extractor instance
@@ -20,10 +32,30 @@ func NewExtractor(page *rod.Page) *Extractor {
 	}
 }
 
+// SetDocument hands over the rendered HTML and metadata the caller already
+// read, so the content level does not fetch and scan the document again
+func (e *Extractor) SetDocument(html string, meta metadata.Metadata) {
+	e.doc = html
+	e.meta = meta
+}
+
 // Extract extracts content based on level
 // level: extraction level (full/html/body/content/xpath/css)
 // selector: selector (only for xpath and css levels)
 func (e *Extractor) Extract(level, selector string) (string, error) {
+	if e.scoped() {
+		switch level {
+		case "css", "xpath":
+			matches, _, err := e.Select(selector, level == "xpath", "", false)
+			if err != nil {
+				return "", err
+			}
+			return strings.Join(matches, "\n"), nil
+		default:
+			e.inline()
+		}
+	}
+
 	switch level {
 	case "full":
 		return e.extractFull()
@@ -42,6 +74,58 @@ func (e *Extractor) Extract(level, selector string) (string, error) {
 	}
 }
 
+// Article returns the Readability result of the last "content" extraction,
+// or nil if Readability was not used
+func (e *Extractor) Article() *readability.Article {
+	return e.article
+}
+
+// ExtractSchema evaluates s against the page and, with frames, each nested
+// frame, and returns the transformed records; pageURL resolves absolute_url
+// transforms
+func (e *Extractor) ExtractSchema(s *schema.Schema, pageURL string) ([]map[string]any, error) {
+	script, err := s.Script()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []map[string]any
+	for i, doc := range e.docs() {
+		result, err := doc.Timeout(10*time.Second).Eval(script, e.pierce)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to evaluate schema: %w", err)
+			}
+			// A frame that navigated away or never loaded is skipped
+			continue
+		}
+		var records []map[string]any
+		if err := doc.MustObjectToJSON(result).Unmarshal(&records); err != nil {
+			return nil, fmt.Errorf("failed to parse schema records: %w", err)
+		}
+		raw = append(raw, records...)
+	}
+	return s.Apply(raw, pageURL), nil
+}
+
+// Links returns the absolute http(s) URLs of all anchors in the page
+func (e *Extractor) Links() ([]string, error) {
+	result, err := e.page.Timeout(10 * time.Second).Eval(`() => {
+		return Array.from(document.querySelectorAll('a[href]'))
+			.map(a => a.href)
+			.filter(href => href.startsWith('http://') || href.startsWith('https://'));
+	}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract links: %w", err)
+	}
+
+	var links []string
+	if err := e.page.MustObjectToJSON(result).Unmarshal(&links); err != nil {
+		return nil, fmt.Errorf("failed to parse links: %w", err)
+	}
+	return links, nil
+}
+
 // extractFull extracts complete HTML document (including head)
 func (e *Extractor) extractFull() (string, error) {
 	// Use JavaScript to get complete HTML document, including DOCTYPE
@@ -88,18 +172,20 @@ func (e *Extractor) extractBody() (string, error) {
 
 // extractContent intelligently identifies main content (Readability+selector+fallback strategy)
 func (e *Extractor) extractContent() (string, error) {
-	// Strategy 1: Try to use Readability algorithm
-	hasReadability, err := e.page.Timeout(5 * time.Second).Eval(`() => typeof window.readability !== 'undefined'`)
-	if err == nil && e.page.MustObjectToJSON(hasReadability).Bool() {
-		result, err := e.page.Timeout(5 * time.Second).Eval(`() => {
-			const article = new Readability(document).parse();
-			return article ? article.content : '';
-		}`)
-		if err == nil {
-			content := e.page.MustObjectToJSON(result).String()
-			if content != "" {
-				return content, nil
-			}
+	// Strategy 1: Run the Readability port on the rendered document
+	doc := e.doc
+	if doc == "" {
+		doc, _ = e.page.Timeout(10 * time.Second).HTML()
+	}
+	if doc != "" {
+		pageURL := ""
+		if info, err := e.page.Info(); err == nil {
+			pageURL = info.URL
+		}
+		article, err := readability.Parse(doc, pageURL, e.meta)
+		if err == nil && article.Length > 0 {
+			e.article = article
+			return article.Content, nil
 		}
 	}
 
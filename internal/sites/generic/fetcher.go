@@ -1,35 +1,113 @@
 package generic
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"durl/internal/actions"
 	"durl/internal/browser"
+	"durl/internal/scraper"
 
 	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
 )
 
 // WaitStrategy wait strategy type
 type WaitStrategy string
 
 const (
-	WaitStrategyLoad    WaitStrategy = "load"    // Wait for page to fully load
-	WaitStrategyElement WaitStrategy = "element" // Wait for specific element to appear
-	WaitStrategyTime    WaitStrategy = "time"    // Wait for fixed time
+	WaitStrategyLoad        WaitStrategy = "load"        // Wait for page to fully load
+	WaitStrategyElement     WaitStrategy = "element"     // Wait for specific element to appear
+	WaitStrategyTime        WaitStrategy = "time"        // Wait for fixed time
+	WaitStrategyNetworkIdle WaitStrategy = "networkidle" // Wait until no requests are in flight for 500ms
+	WaitStrategyJS          WaitStrategy = "js"          // Wait until a JavaScript expression is truthy
+	WaitStrategyText        WaitStrategy = "text"        // Wait until the page text contains a string
+	WaitStrategyURL         WaitStrategy = "url"         // Wait until the page URL matches a regular expression
 )
 
+// DefaultWait is the default --wait-for. Its networkidle step is best
+// effort: pages that never go quiet (long polling, beacons, streaming) are
+// extracted once the timeout expires instead of failing.
+const DefaultWait = "load+networkidle"
+
+// networkIdleTime is how long the network must be quiet for networkidle
+const networkIdleTime = 500 * time.Millisecond
+
+// WaitStep is one strategy of a (possibly composite) wait
+type WaitStep struct {
+	Strategy   WaitStrategy
+	Target     string // selector, milliseconds, expression, text or URL pattern
+	BestEffort bool   // a timeout is a warning, not an error
+}
+
+// ParseWait parses a wait strategy such as "load" or "element+networkidle".
+// Strategies joined by "+" run in order; each one that needs a target takes
+// the next of targets, which are given by repeating --wait-target.
+func ParseWait(spec string, targets []string) ([]WaitStep, error) {
+	var steps []WaitStep
+	next := 0
+	for _, name := range strings.Split(spec, "+") {
+		strategy := WaitStrategy(strings.TrimSpace(name))
+		switch strategy {
+		case WaitStrategyLoad, WaitStrategyNetworkIdle:
+			steps = append(steps, WaitStep{
+				Strategy:   strategy,
+				BestEffort: strategy == WaitStrategyNetworkIdle && spec == DefaultWait,
+			})
+			continue
+		case WaitStrategyElement, WaitStrategyTime, WaitStrategyJS, WaitStrategyText, WaitStrategyURL:
+		default:
+			return nil, fmt.Errorf("invalid wait strategy: %s", name)
+		}
+
+		if next >= len(targets) || targets[next] == "" {
+			return nil, fmt.Errorf("--wait-target is required when using '%s' wait strategy", strategy)
+		}
+		step := WaitStep{Strategy: strategy, Target: targets[next]}
+		next++
+		switch strategy {
+		case WaitStrategyTime:
+			if _, err := strconv.Atoi(step.Target); err != nil {
+				return nil, fmt.Errorf("invalid wait time '%s': must be milliseconds", step.Target)
+			}
+		case WaitStrategyURL:
+			if _, err := regexp.Compile(step.Target); err != nil {
+				return nil, fmt.Errorf("invalid wait URL pattern '%s': %w", step.Target, err)
+			}
+		}
+		steps = append(steps, step)
+	}
+	if next < len(targets) {
+		return nil, fmt.Errorf("too many --wait-target values for wait strategy '%s'", spec)
+	}
+	return steps, nil
+}
+
+// WaitSteps parses the wait strategy and targets of opts
+func WaitSteps(opts scraper.Options) ([]WaitStep, error) {
+	return ParseWait(opts.WaitFor, opts.WaitTargets)
+}
+
 // FetchResult fetch result
 type FetchResult struct {
 	Page     *rod.Page     // Page object
 	Title    string        // Page title
 	URL      string        // Final URL
 	LoadTime time.Duration // Load time
+	Response Response      // Main document status, headers and redirect chain
 }
 
 // Fetcher page fetcher
 type Fetcher struct {
 	browser *browser.Browser
+	actions *actions.Script // run after the wait strategy; nil runs none
 }
 
 // NewFetcher creates a new Fetcher instance
@@ -44,15 +122,19 @@ func (f *Fetcher) SetBrowser(browser *browser.Browser) {
 	f.browser = browser
 }
 
+// SetActions sets the actions script run on every fetched page
+func (f *Fetcher) SetActions(s *actions.Script) {
+	f.actions = s
+}
+
 // Fetch executes page fetching
 // url: target URL
 // method: HTTP method (GET, POST, PUT, DELETE, etc.)
 // headers: request header map
 // body: request body (for POST/PUT methods)
-// waitStrategy: wait strategy (load/element/time)
-// waitTarget: wait target (selector for element strategy or milliseconds for time strategy)
-// timeout: timeout duration
-func (f *Fetcher) Fetch(url, method string, headers map[string]string, body string, waitStrategy WaitStrategy, waitTarget string, timeout time.Duration) (*FetchResult, error) {
+// wait: wait steps applied after navigation (see ParseWait)
+// timeout: timeout duration, applied to navigation and to the wait as a whole
+func (f *Fetcher) Fetch(url, method string, headers map[string]string, body string, wait []WaitStep, timeout time.Duration) (*FetchResult, error) {
 	startTime := time.Now()
 
 	// Create new page (no timeout to avoid affecting subsequent operations)
@@ -76,102 +158,47 @@ func (f *Fetcher) Fetch(url, method string, headers map[string]string, body stri
 		defer cleanup()
 	}
 
+	// Capture status, headers and redirects of the main document
+	recorder := recordResponse(page)
+	defer recorder.stop()
+
 	// Execute HTTP request
 	switch method {
 	case "GET":
 		if err := page.Timeout(timeout).Navigate(url); err != nil {
 			page.Close()
-			return nil, fmt.Errorf("failed to navigate: %w", err)
-		}
-	case "POST", "PUT", "DELETE", "PATCH":
-		// For methods requiring request body, use JavaScript fetch API
-		// Then write response content to page
-		var responseText string
-		if body != "" {
-			// Case with request body
-			result, err := page.Timeout(timeout).Eval(fmt.Sprintf(`() => {
-				return fetch('%s', {
-					method: '%s',
-					headers: %s,
-					body: %s
-				}).then(r => r.text());
-			}`, url, method, headersToJS(headers), stringToJS(body)))
-			if err != nil {
-				page.Close()
-				return nil, fmt.Errorf("failed to execute %s request: %w", method, err)
-			}
-			responseText = page.MustObjectToJSON(result).String()
-		} else {
-			// Case without request body
-			result, err := page.Timeout(timeout).Eval(fmt.Sprintf(`() => {
-				return fetch('%s', {
-					method: '%s',
-					headers: %s
-				}).then(r => r.text());
-			}`, url, method, headersToJS(headers)))
-			if err != nil {
-				page.Close()
-				return nil, fmt.Errorf("failed to execute %s request: %w", method, err)
-			}
-			responseText = page.MustObjectToJSON(result).String()
+			return nil, fmt.Errorf("failed to navigate: %w", navigationError(url, err))
 		}
-
-		// Write response content to page
-		_, err := page.Eval(fmt.Sprintf(`() => {
-			document.open();
-			document.write(%s);
-			document.close();
-		}`, stringToJS(responseText)))
-		if err != nil {
-			page.Close()
-			return nil, fmt.Errorf("failed to write response to page: %w", err)
-		}
-	case "HEAD", "OPTIONS":
-		// For HEAD and OPTIONS, use JavaScript fetch API
-		// These methods don't return response body, only response headers
-		result, err := page.Timeout(timeout).Eval(fmt.Sprintf(`() => {
-			return fetch('%s', {
-				method: '%s',
-				headers: %s
-			}).then(r => {
-				// Convert response header information to text
-				let headersText = '';
-				r.headers.forEach((value, key) => {
-					headersText += key + ': ' + value + '\\n';
-				});
-				return headersText || 'No headers returned';
-			});
-		}`, url, method, headersToJS(headers)))
-		if err != nil {
+	case "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		// Perform a genuine top-level navigation whose document request is
+		// rewritten in flight, so the result renders like a form submission
+		if err := navigateWithRequest(f.browser, page, url, method, headers, body, timeout); err != nil {
 			page.Close()
-			return nil, fmt.Errorf("failed to execute %s request: %w", method, err)
-		}
-
-		// Write response header information to page
-		headersText := page.MustObjectToJSON(result).String()
-		_, err = page.Eval(fmt.Sprintf(`() => {
-			document.open();
-			document.write(%s);
-			document.close();
-		}`, stringToJS(headersText)))
-		if err != nil {
-			page.Close()
-			return nil, fmt.Errorf("failed to write response to page: %w", err)
+			return nil, fmt.Errorf("failed to execute %s request: %w", method, navigationError(url, err))
 		}
 	default:
 		// Default to GET
 		if err := page.Timeout(timeout).Navigate(url); err != nil {
 			page.Close()
-			return nil, fmt.Errorf("failed to navigate: %w", err)
+			return nil, fmt.Errorf("failed to navigate: %w", navigationError(url, err))
 		}
 	}
 
 	// Apply wait strategy
-	if err := f.applyWaitStrategy(page, waitStrategy, waitTarget); err != nil {
+	if err := f.applyWait(page, wait, timeout); err != nil {
 		page.Close()
 		return nil, fmt.Errorf("wait strategy failed: %w", err)
 	}
 
+	// Run scripted actions (dismissing popups, clicking tabs, ...) once the
+	// page is ready
+	if f.actions != nil {
+		if err := f.runActions(page, f.actions, timeout); err != nil {
+			page.Close()
+			return nil, err
+		}
+	}
+
 	// Get page metadata
 	title, err := page.Eval(`() => document.title`)
 	if err != nil {
@@ -190,39 +217,97 @@ func (f *Fetcher) Fetch(url, method string, headers map[string]string, body stri
 		Title:    page.MustObjectToJSON(title).String(),
 		URL:      finalURL,
 		LoadTime: loadTime,
+		Response: recorder.stop(),
 	}
 
 	return result, nil
 }
 
-// applyWaitStrategy applies wait strategy
-func (f *Fetcher) applyWaitStrategy(page *rod.Page, strategy WaitStrategy, target string) error {
-	switch strategy {
+// applyWait runs the wait steps in order within a shared timeout
+func (f *Fetcher) applyWait(page *rod.Page, steps []WaitStep, timeout time.Duration) error {
+	p := page.Timeout(timeout)
+	defer p.CancelTimeout()
+
+	for _, step := range steps {
+		if err := f.applyWaitStrategy(p, step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyWaitStrategy applies one wait step
+func (f *Fetcher) applyWaitStrategy(page *rod.Page, step WaitStep) error {
+	target := step.Target
+	switch step.Strategy {
 	case WaitStrategyLoad:
 		// Wait for page to fully load
 		if err := page.WaitLoad(); err != nil {
 			return fmt.Errorf("failed to wait for page load: %w", err)
 		}
 
+	case WaitStrategyNetworkIdle:
+		// Wait until no requests (other than images and media) are pending
+		page.WaitRequestIdle(
+			networkIdleTime, nil, nil,
+			[]proto.NetworkResourceType{proto.NetworkResourceTypeImage, proto.NetworkResourceTypeMedia},
+		)()
+		if err := page.GetContext().Err(); err != nil {
+			if step.BestEffort {
+				fmt.Fprintf(os.Stderr, "Warning: network not idle before timeout, extracting anyway\n")
+				return nil
+			}
+			return fmt.Errorf("failed to wait for network idle: %w", err)
+		}
+
 	case WaitStrategyElement:
 		// Wait for specific element to appear
-		if target == "" {
-			return fmt.Errorf("wait target is required for element strategy")
-		}
 		if _, err := page.Element(target); err != nil {
 			return fmt.Errorf("failed to wait for element '%s': %w", target, err)
 		}
 
 	case WaitStrategyTime:
 		// Wait for fixed time
-		if target == "" {
-			return fmt.Errorf("wait target is required for time strategy")
-		}
 		duration, err := time.ParseDuration(target + "ms")
 		if err != nil {
 			return fmt.Errorf("invalid wait time '%s': %w", target, err)
 		}
-		time.Sleep(duration)
+		select {
+		case <-time.After(duration):
+		case <-page.GetContext().Done():
+			return fmt.Errorf("failed to wait %s: %w", duration, page.GetContext().Err())
+		}
+
+	case WaitStrategyJS:
+		// Exceptions count as falsy so expressions may refer to globals that
+		// the page defines later
+		js := `() => { try { return !!(` + target + `) } catch (e) { return false } }`
+		if err := page.Wait(rod.Eval(js)); err != nil {
+			return fmt.Errorf("failed to wait for expression '%s': %w", target, err)
+		}
+
+	case WaitStrategyText:
+		js := `t => !!document.body && document.body.innerText.includes(t)`
+		if err := page.Wait(rod.Eval(js, target)); err != nil {
+			return fmt.Errorf("failed to wait for text '%s': %w", target, err)
+		}
+
+	case WaitStrategyURL:
+		// Polled from Go so the wait survives the navigations it waits for
+		re, err := regexp.Compile(target)
+		if err != nil {
+			return fmt.Errorf("invalid wait URL pattern '%s': %w", target, err)
+		}
+		for {
+			if info, err := page.Info(); err == nil && re.MatchString(info.URL) {
+				break
+			}
+			select {
+			case <-time.After(100 * time.Millisecond):
+			case <-page.GetContext().Done():
+				return fmt.Errorf("failed to wait for URL '%s': %w", target, page.GetContext().Err())
+			}
+		}
 
 	default:
 		// Default to wait for page load
@@ -234,22 +319,72 @@ func (f *Fetcher) applyWaitStrategy(page *rod.Page, strategy WaitStrategy, targe
 	return nil
 }
 
-// headersToJS converts request header map to JavaScript object string
-func headersToJS(headers map[string]string) string {
-	if len(headers) == 0 {
-		return "{}"
+// navigateWithRequest navigates page to url and uses the page's request
+// interceptor to rewrite the method, headers and body of the first document
+// request before any other handler (mock or block rules) sees it. Redirects
+// and subresources continue untouched, and the rewrite is removed once the
+// navigation has committed.
+func navigateWithRequest(b *browser.Browser, page *rod.Page, url, method string, headers map[string]string, body string, timeout time.Duration) error {
+	ic, err := b.Intercept(page)
+	if err != nil {
+		return err
 	}
-	result := "{"
-	for k, v := range headers {
-		result += fmt.Sprintf(`"%s": "%s",`, k, v)
+	var rewritten int32
+	remove := ic.Rewrite(func(ctx *rod.Hijack) bool {
+		if !ctx.Request.IsNavigation() || !atomic.CompareAndSwapInt32(&rewritten, 0, 1) {
+			return false
+		}
+		req := ctx.Request.Req()
+		req.Method = method
+		req.Header = http.Header{}
+		for _, e := range requestHeaders(ctx.Request.Headers(), headers, body) {
+			req.Header[e.Name] = []string{e.Value}
+		}
+		ctx.Request.SetBody(body)
+		return true
+	})
+	defer remove()
+
+	return page.Timeout(timeout).Navigate(url)
+}
+
+// navigationError converts Chromium navigation failures (DNS, connection
+// refused, TLS, ...) into scraper.NetworkError so callers can classify them
+func navigationError(url string, err error) error {
+	var navErr *rod.NavigationError
+	if errors.As(err, &navErr) {
+		return &scraper.NetworkError{URL: url, Reason: navErr.Reason}
 	}
-	result = result[:len(result)-1] + "}"
-	return result
+	return err
 }
 
-// stringToJS converts a Go string to a JavaScript string literal using JSON encoding
-// to correctly escape all special characters.
-func stringToJS(s string) string {
-	quoted, _ := json.Marshal(s)
-	return string(quoted)
+// requestHeaders merges the browser's original request headers with the user
+// supplied ones. A body without an explicit Content-Type is sent as form data,
+// matching curl's -d behaviour.
+func requestHeaders(original proto.NetworkHeaders, extra map[string]string, body string) []*proto.FetchHeaderEntry {
+	merged := make(map[string]string, len(original)+len(extra)+1)
+	names := make(map[string]string, len(original)+len(extra)+1) // lower-case name -> canonical name
+	set := func(k, v string) {
+		lower := strings.ToLower(k)
+		if prev, ok := names[lower]; ok {
+			delete(merged, prev)
+		}
+		names[lower] = k
+		merged[k] = v
+	}
+	for k, v := range original {
+		set(k, v.String())
+	}
+	for k, v := range extra {
+		set(k, v)
+	}
+	if _, ok := names["content-type"]; !ok && body != "" {
+		set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	entries := make([]*proto.FetchHeaderEntry, 0, len(merged))
+	for k, v := range merged {
+		entries = append(entries, &proto.FetchHeaderEntry{Name: k, Value: v})
+	}
+	return entries
 }
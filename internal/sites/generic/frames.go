@@ -0,0 +1,202 @@
+package generic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// SetScope widens extraction beyond the top-level light DOM: pierce makes
+// CSS selectors match inside open shadow roots, frames searches iframes
+// (same- and cross-origin) too. Whole-page levels inline that content.
+func (e *Extractor) SetScope(pierce, frames bool) {
+	e.pierce = pierce
+	e.frames = frames
+}
+
+// scoped reports whether SetScope widened extraction
+func (e *Extractor) scoped() bool {
+	return e.pierce || e.frames
+}
+
+// reset forgets what was learned about the previous document, after
+// pagination replaced or extended it
+func (e *Extractor) reset() {
+	e.inlined = false
+	e.article = nil
+	e.doc = ""
+}
+
+// docs returns the page and, with frames, its nested frames, depth first
+func (e *Extractor) docs() []*rod.Page {
+	docs := []*rod.Page{e.page}
+	if e.frames {
+		docs = append(docs, frameTree(e.page)...)
+	}
+	return docs
+}
+
+// inline rewrites the live document once per page so whole-page extraction
+// sees frame and shadow content: iframes are replaced by their body and shadow hosts
+// by their composed content
+func (e *Extractor) inline() {
+	if e.inlined {
+		return
+	}
+	e.inlined = true
+	e.doc = ""
+	if e.frames {
+		inlineFrames(e.page, e.pierce)
+	}
+	if e.pierce {
+		flattenShadow(e.page)
+	}
+}
+
+// Select returns one entry per node matching a CSS selector or XPath in the
+// page and, with frames, in each nested frame: the attribute named attr, the
+// node's text, or else its outer HTML. values reports whether the entries are
+// values rather than element HTML, as for --attr, --text and XPath results
+// that are attributes, text nodes, strings or numbers.
+func (e *Extractor) Select(selector string, xpath bool, attr string, text bool) (matches []string, values bool, err error) {
+	values = attr != "" || text
+	for i, doc := range e.docs() {
+		css, xp := selector, ""
+		if xpath {
+			css, xp = "", selector
+		}
+		res, err := doc.Timeout(10*time.Second).Eval(selectScript, css, xp, e.pierce, attr, text)
+		if err != nil {
+			if i == 0 {
+				return nil, false, fmt.Errorf("failed to query selector: %w", err)
+			}
+			// A frame that navigated away or never loaded is skipped
+			continue
+		}
+		var found struct {
+			Matches []string `json:"matches"`
+			Values  bool     `json:"values"`
+		}
+		if err := doc.MustObjectToJSON(res).Unmarshal(&found); err != nil {
+			return nil, false, fmt.Errorf("failed to parse matches: %w", err)
+		}
+		matches = append(matches, found.Matches...)
+		values = values || found.Values
+	}
+	return matches, values, nil
+}
+
+// frameTree returns the frames nested in page, depth first
+func frameTree(page *rod.Page) []*rod.Page {
+	els, err := page.Elements("iframe, frame")
+	if err != nil {
+		return nil
+	}
+	var frames []*rod.Page
+	for _, el := range els {
+		frame, err := el.Frame()
+		if err != nil {
+			continue
+		}
+		frames = append(frames, frame)
+		frames = append(frames, frameTree(frame)...)
+	}
+	return frames
+}
+
+// inlineFrames replaces each frame element with a div holding the frame's
+// body, innermost frames first
+func inlineFrames(page *rod.Page, pierce bool) {
+	els, err := page.Elements("iframe, frame")
+	if err != nil {
+		return
+	}
+	for _, el := range els {
+		frame, err := el.Frame()
+		if err != nil {
+			continue
+		}
+		inlineFrames(frame, pierce)
+		if pierce {
+			flattenShadow(frame)
+		}
+		res, err := frame.Timeout(10 * time.Second).Eval(`() => document.body ? document.body.innerHTML : ''`)
+		if err != nil {
+			continue
+		}
+		_, _ = el.Timeout(10*time.Second).Eval(`(h) => {
+			const div = document.createElement('div');
+			div.setAttribute('data-frame-src', this.src || '');
+			div.innerHTML = h;
+			this.replaceWith(div);
+		}`, res.Value.String())
+	}
+}
+
+// flattenShadow replaces open shadow hosts with their composed content
+func flattenShadow(page *rod.Page) {
+	_, _ = page.Timeout(10 * time.Second).Eval(flattenScript)
+}
+
+// nodeValueScript returns the output of a matched node and whether it is a
+// value rather than element HTML; a missing attribute yields null
+const nodeValueScript = `(n, attr, text) => {
+	if (n.nodeType !== Node.ELEMENT_NODE) return [text ? n.textContent.trim() : n.textContent, true];
+	if (attr) return [n.getAttribute(attr), true];
+	if (text) return [(n.innerText !== undefined ? n.innerText : n.textContent).trim(), true];
+	return [n.outerHTML, false];
+}`
+
+// selectScript returns the output of CSS (optionally shadow-piercing) or
+// XPath matches in the document; XPath string, number and boolean results
+// are a single value
+const selectScript = `(sel, xpath, pierce, attr, text) => {
+	const value = ` + nodeValueScript + `;
+	const out = {matches: [], values: false};
+	const add = n => {
+		const [v, isValue] = value(n, attr, text);
+		if (v === null) return;
+		out.matches.push(v);
+		out.values = out.values || isValue;
+	};
+	if (xpath) {
+		const res = document.evaluate(xpath, document, null, XPathResult.ANY_TYPE, null);
+		switch (res.resultType) {
+		case XPathResult.NUMBER_TYPE: return {matches: [String(res.numberValue)], values: true};
+		case XPathResult.STRING_TYPE: return {matches: [res.stringValue], values: true};
+		case XPathResult.BOOLEAN_TYPE: return {matches: [String(res.booleanValue)], values: true};
+		}
+		const nodes = document.evaluate(xpath, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+		for (let i = 0; i < nodes.snapshotLength; i++) add(nodes.snapshotItem(i));
+		return out;
+	}
+	const walk = root => {
+		root.querySelectorAll(sel).forEach(add);
+		if (pierce) root.querySelectorAll('*').forEach(e => { if (e.shadowRoot) walk(e.shadowRoot); });
+	};
+	walk(document);
+	return out;
+}`
+
+// flattenScript replaces every open shadow host, innermost first, with a div
+// holding its shadow content, slots filled with the host's assigned children
+const flattenScript = `() => {
+	const flatten = root => {
+		Array.from(root.querySelectorAll('*')).reverse().forEach(host => {
+			if (!host.shadowRoot) return;
+			flatten(host.shadowRoot);
+			const div = document.createElement('div');
+			div.setAttribute('data-shadow-host', host.localName);
+			div.innerHTML = host.shadowRoot.innerHTML;
+			div.querySelectorAll('slot').forEach(slot => {
+				const name = slot.getAttribute('name') || '';
+				const assigned = Array.from(host.childNodes).filter(n =>
+					(n.nodeType === Node.ELEMENT_NODE ? n.getAttribute('slot') || '' : '') === name);
+				slot.replaceWith(...(assigned.length ? assigned.map(n => n.cloneNode(true)) : slot.childNodes));
+			});
+			host.replaceWith(div);
+		});
+	};
+	flatten(document);
+}`
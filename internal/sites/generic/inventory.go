@@ -0,0 +1,241 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"durl/internal/metadata"
+)
+
+// Inventory lists what a rendered page links to and loads (--level links)
+type Inventory struct {
+	Links       []Link     `json:"links"`
+	Images      []Image    `json:"images"`
+	Scripts     []Resource `json:"scripts"`
+	Stylesheets []Resource `json:"stylesheets"`
+	Iframes     []Resource `json:"iframes"`
+}
+
+// Link is an <a> or <area> with an href
+type Link struct {
+	URL      string `json:"url"` // absolute
+	Text     string `json:"text"`
+	Rel      string `json:"rel,omitempty"`
+	Internal bool   `json:"internal"` // same host as the page
+}
+
+// Image is an <img> element
+type Image struct {
+	URL    string `json:"url"` // currentSrc, so srcset picks are reported
+	Alt    string `json:"alt"`
+	Width  int    `json:"width"` // natural size when loaded, else layout size
+	Height int    `json:"height"`
+}
+
+// Resource is an external script, stylesheet or iframe
+type Resource struct {
+	URL   string `json:"url"`
+	Type  string `json:"type,omitempty"`  // script type or stylesheet media
+	Title string `json:"title,omitempty"` // iframe title
+}
+
+// InventoryContent holds a --level links inventory instead of
+// level-extracted HTML
+type InventoryContent struct {
+	pageInfo
+	inventory *Inventory
+}
+
+// NewInventoryContent creates an InventoryContent from an inventory
+func NewInventoryContent(inv *Inventory, title, url string, loadTime time.Duration, response Response, meta metadata.Metadata) *InventoryContent {
+	return &InventoryContent{
+		pageInfo: pageInfo{
+			title:    title,
+			url:      url,
+			loadTime: loadTime,
+			response: response,
+			meta:     meta,
+		},
+		inventory: inv,
+	}
+}
+
+// Inventory returns the link and asset inventory
+func (p *InventoryContent) Inventory() *Inventory {
+	return p.inventory
+}
+
+// ToHTML renders the inventory as HTML lists
+func (p *InventoryContent) ToHTML() (string, error) {
+	inv := p.inventory
+	var sb strings.Builder
+	sb.WriteString("<h2>Links</h2>\n<ul>\n")
+	for _, l := range inv.Links {
+		sb.WriteString(fmt.Sprintf("<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(l.URL), html.EscapeString(l.Text)))
+	}
+	sb.WriteString("</ul>\n<h2>Images</h2>\n<ul>\n")
+	for _, img := range inv.Images {
+		sb.WriteString(fmt.Sprintf("<li><img src=\"%s\" alt=\"%s\" width=\"%d\" height=\"%d\"></li>\n",
+			html.EscapeString(img.URL), html.EscapeString(img.Alt), img.Width, img.Height))
+	}
+	sb.WriteString("</ul>\n")
+	for _, kind := range []struct {
+		title string
+		list  []Resource
+	}{{"Scripts", inv.Scripts}, {"Stylesheets", inv.Stylesheets}, {"Iframes", inv.Iframes}} {
+		sb.WriteString("<h2>" + kind.title + "</h2>\n<ul>\n")
+		for _, r := range kind.list {
+			sb.WriteString("<li>" + html.EscapeString(r.URL) + "</li>\n")
+		}
+		sb.WriteString("</ul>\n")
+	}
+	return sb.String(), nil
+}
+
+// ToText lists the distinct link targets, one per line
+func (p *InventoryContent) ToText() (string, error) {
+	return strings.Join(p.inventory.SeedURLs(), "\n"), nil
+}
+
+// ToMarkdown renders the inventory as Markdown sections
+func (p *InventoryContent) ToMarkdown() (string, error) {
+	inv := p.inventory
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("## Links (%d)\n\n| URL | Text | Rel | Internal |\n| --- | --- | --- | --- |\n", len(inv.Links)))
+	for _, l := range inv.Links {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %t |\n", escape.Replace(l.URL), escape.Replace(l.Text), escape.Replace(l.Rel), l.Internal))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## Images (%d)\n\n| URL | Alt | Size |\n| --- | --- | --- |\n", len(inv.Images)))
+	for _, img := range inv.Images {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %dx%d |\n", escape.Replace(img.URL), escape.Replace(img.Alt), img.Width, img.Height))
+	}
+
+	for _, kind := range []struct {
+		title string
+		list  []Resource
+	}{{"Scripts", inv.Scripts}, {"Stylesheets", inv.Stylesheets}, {"Iframes", inv.Iframes}} {
+		sb.WriteString(fmt.Sprintf("\n## %s (%d)\n\n", kind.title, len(kind.list)))
+		for _, r := range kind.list {
+			sb.WriteString("- " + r.URL + "\n")
+		}
+	}
+	return p.withFrontMatter(sb.String()), nil
+}
+
+// ToJSON encodes the inventory object
+func (p *InventoryContent) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(p.inventory, "", "  ")
+}
+
+// ToCSV writes one row per inventory entry
+func (p *InventoryContent) ToCSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(inventoryCSVHeader)
+	_ = w.WriteAll(p.inventory.rows())
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Snapshot encodes the inventory for the response cache
+func (p *InventoryContent) Snapshot() ([]byte, error) {
+	s := p.snapshot(kindInventory)
+	s.Inventory = p.inventory
+	return json.Marshal(s)
+}
+
+// inventoryScript collects the inventory from the rendered DOM
+const inventoryScript = `() => {
+	const text = el => (el.innerText || el.getAttribute('aria-label') || el.title || '').trim().replace(/\s+/g, ' ');
+	return {
+		links: Array.from(document.querySelectorAll('a[href], area[href]')).map(a => ({
+			url: a.href, text: text(a), rel: a.getAttribute('rel') || '',
+		})),
+		images: Array.from(document.images).filter(img => img.currentSrc || img.src).map(img => ({
+			url: img.currentSrc || img.src, alt: img.alt || '',
+			width: img.naturalWidth || img.width, height: img.naturalHeight || img.height,
+		})),
+		scripts: Array.from(document.querySelectorAll('script[src]')).map(s => ({url: s.src, type: s.type || ''})),
+		stylesheets: Array.from(document.querySelectorAll('link[rel~="stylesheet"][href]')).map(l => ({url: l.href, type: l.media || ''})),
+		iframes: Array.from(document.querySelectorAll('iframe[src]')).map(f => ({url: f.src, title: f.title || ''})),
+	};
+}`
+
+// Inventory collects links, images, scripts, stylesheets and iframes.
+// Links on the same host as pageURL are marked internal.
+func (e *Extractor) Inventory(pageURL string) (*Inventory, error) {
+	result, err := e.page.Timeout(10 * time.Second).Eval(inventoryScript)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect links: %w", err)
+	}
+	var inv Inventory
+	if err := e.page.MustObjectToJSON(result).Unmarshal(&inv); err != nil {
+		return nil, fmt.Errorf("failed to parse links: %w", err)
+	}
+
+	host := ""
+	if u, err := url.Parse(pageURL); err == nil {
+		host = strings.ToLower(u.Hostname())
+	}
+	for i := range inv.Links {
+		if u, err := url.Parse(inv.Links[i].URL); err == nil {
+			inv.Links[i].Internal = host != "" && strings.ToLower(u.Hostname()) == host
+		}
+	}
+	return &inv, nil
+}
+
+// SeedURLs returns the distinct http(s) link targets, without fragments,
+// in page order; this is the text output so it can feed --input-file
+func (inv *Inventory) SeedURLs() []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, l := range inv.Links {
+		u, err := url.Parse(l.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		u.Fragment = ""
+		s := u.String()
+		if !seen[s] {
+			seen[s] = true
+			urls = append(urls, s)
+		}
+	}
+	return urls
+}
+
+// inventoryCSVHeader is the column set shared by all asset kinds
+var inventoryCSVHeader = []string{"kind", "url", "text", "rel", "internal", "alt", "width", "height", "type"}
+
+// rows flattens the inventory into inventoryCSVHeader rows
+func (inv *Inventory) rows() [][]string {
+	var rows [][]string
+	for _, l := range inv.Links {
+		rows = append(rows, []string{"link", l.URL, l.Text, l.Rel, strconv.FormatBool(l.Internal), "", "", "", ""})
+	}
+	for _, img := range inv.Images {
+		rows = append(rows, []string{"image", img.URL, "", "", "", img.Alt, strconv.Itoa(img.Width), strconv.Itoa(img.Height), ""})
+	}
+	for _, kind := range []struct {
+		name string
+		list []Resource
+	}{{"script", inv.Scripts}, {"stylesheet", inv.Stylesheets}, {"iframe", inv.Iframes}} {
+		for _, r := range kind.list {
+			rows = append(rows, []string{kind.name, r.URL, r.Title, "", "", "", "", "", r.Type})
+		}
+	}
+	return rows
+}
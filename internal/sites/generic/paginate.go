@@ -0,0 +1,428 @@
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"durl/internal/metadata"
+	"durl/internal/scraper"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Pagination modes
+const (
+	PaginateScroll = "scroll" // scroll to the bottom to load more (infinite scroll)
+	PaginateNext   = "next"   // click a "next page" control
+)
+
+// ParsePagination parses --paginate: "scroll" or "next=<selector>"
+func ParsePagination(spec string) (scraper.Pagination, error) {
+	switch {
+	case spec == "":
+		return scraper.Pagination{}, nil
+	case spec == PaginateScroll:
+		return scraper.Pagination{Mode: PaginateScroll}, nil
+	case strings.HasPrefix(spec, PaginateNext+"="):
+		sel := strings.TrimSpace(strings.TrimPrefix(spec, PaginateNext+"="))
+		if sel == "" {
+			return scraper.Pagination{}, fmt.Errorf("--paginate next= requires a selector")
+		}
+		return scraper.Pagination{Mode: PaginateNext, Next: sel}, nil
+	}
+	return scraper.Pagination{}, fmt.Errorf("invalid --paginate value %q (scroll or next=<selector>)", spec)
+}
+
+// item is one list item (css/xpath levels) or record (--schema) with the
+// key it is de-duplicated by
+type item struct {
+	key    string
+	html   string // outer HTML, or the value with --attr/--text
+	value  bool   // html is a value rather than element HTML
+	record map[string]any
+}
+
+// paginate extracts the current page, then advances through further pages
+// until there are none, a page adds no new items or repeats an earlier one,
+// or MaxPages is reached.
+// Items of the css/xpath levels and schema records are merged without
+// repeats; other levels are concatenated page by page.
+func paginate(result *FetchResult, extractor *Extractor, opts scraper.Options, meta metadata.Metadata) (Content, error) {
+	p := opts.Pagination
+	itemized := opts.Schema != nil || opts.Level == "css" || opts.Level == "xpath"
+	last := func(n int) bool { return p.MaxPages > 0 && n >= p.MaxPages }
+	visited := map[string]bool{}
+
+	// Infinite scroll keeps earlier content in the document, so whole-page
+	// levels are extracted once everything has loaded
+	if !itemized && p.Mode == PaginateScroll {
+		for n := 1; !last(n); n++ {
+			if !advance(result.Page, p, opts.Timeout, n, visited) {
+				break
+			}
+		}
+		extractor.reset()
+		return extractLevel(extractor, result, opts, meta)
+	}
+
+	var items []item
+	var pages []Content
+	seen := map[string]bool{}
+	for n := 1; ; n++ {
+		current := *result
+		if info, err := result.Page.Info(); err == nil {
+			current.URL = info.URL
+		}
+
+		if itemized {
+			got, err := extractItems(extractor, current.URL, opts)
+			if err != nil {
+				return nil, err
+			}
+			added := 0
+			for _, it := range got {
+				if !seen[it.key] {
+					seen[it.key] = true
+					items = append(items, it)
+					added++
+				}
+			}
+			fmt.Fprintf(os.Stderr, "[paginate] page %d: %d new items (%d total)\n", n, added, len(items))
+			if n > 1 && added == 0 {
+				break
+			}
+		} else {
+			content, err := extractLevel(extractor, &current, opts, meta)
+			if err != nil {
+				return nil, err
+			}
+			pages = append(pages, content)
+			fmt.Fprintf(os.Stderr, "[paginate] page %d: %s\n", n, current.URL)
+		}
+
+		if last(n) || !advance(result.Page, p, opts.Timeout, n, visited) {
+			break
+		}
+		// The next page needs its frames and shadow roots inlined afresh
+		extractor.reset()
+	}
+
+	switch {
+	case opts.Schema != nil:
+		records := make([]map[string]any, len(items))
+		for i, it := range items {
+			records[i] = it.record
+		}
+		if opts.Fail && len(records) == 0 {
+			return nil, fmt.Errorf("%w: schema matched no records", scraper.ErrSelectorNotFound)
+		}
+		return NewRecordsContent(opts.Schema.FieldNames(), records, result.Title, result.URL, result.LoadTime, result.Response, meta), nil
+
+	case itemized:
+		parts := make([]string, len(items))
+		values := opts.Attr != "" || opts.Text
+		for i, it := range items {
+			parts[i] = it.html
+			values = values || it.value
+		}
+		if values {
+			if opts.Fail && len(parts) == 0 {
+				return nil, fmt.Errorf("%w: %s", scraper.ErrSelectorNotFound, opts.Selector)
+			}
+			return NewValuesContent(parts, result.Title, result.URL, result.LoadTime, result.Response, meta), nil
+		}
+		html := strings.Join(parts, "\n")
+		if opts.Fail && html == "" {
+			return nil, fmt.Errorf("%w: %s", scraper.ErrSelectorNotFound, opts.Selector)
+		}
+		return NewPageContent(PageOptions{
+			HTML:     html,
+			Main:     html,
+			Text:     html,
+			Level:    opts.Level,
+			Title:    result.Title,
+			URL:      result.URL,
+			LoadTime: result.LoadTime,
+			Response: result.Response,
+			Meta:     meta,
+		}), nil
+	}
+	return mergePages(pages), nil
+}
+
+// extractItems returns the items of the current page with their keys: the
+// href or text of the --dedupe-key element inside each item, else the item
+// itself (its HTML, or its value with --attr/--text)
+func extractItems(extractor *Extractor, pageURL string, opts scraper.Options) ([]item, error) {
+	sel, xpath := opts.Selector, ""
+	if opts.Level == "xpath" {
+		sel, xpath = "", opts.Selector
+	}
+	if opts.Schema != nil {
+		sel, xpath = opts.Schema.Base, opts.Schema.BaseXPath
+	}
+
+	type rawItem struct {
+		HTML  string `json:"html"`
+		Key   string `json:"key"`
+		Value bool   `json:"value"`
+	}
+	var raw []rawItem
+	for i, doc := range extractor.docs() {
+		res, err := doc.Timeout(10*time.Second).Eval(itemsScript, sel, xpath, extractor.pierce, opts.Pagination.Key, opts.Attr, opts.Text)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to extract items: %w", err)
+			}
+			// A frame that navigated away or never loaded is skipped
+			continue
+		}
+		var got []rawItem
+		if err := doc.MustObjectToJSON(res).Unmarshal(&got); err != nil {
+			return nil, fmt.Errorf("failed to parse items: %w", err)
+		}
+		raw = append(raw, got...)
+	}
+
+	if opts.Schema == nil {
+		items := make([]item, len(raw))
+		for i, r := range raw {
+			items[i] = item{key: r.Key, html: r.HTML, value: r.Value}
+			if r.Key == "" {
+				items[i].key = r.HTML
+			}
+		}
+		return items, nil
+	}
+
+	records, err := extractor.ExtractSchema(opts.Schema, pageURL)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]item, len(records))
+	for i, rec := range records {
+		items[i] = item{record: rec}
+		// Keys line up with records unless the DOM changed in between
+		if len(raw) == len(records) && raw[i].Key != "" {
+			items[i].key = raw[i].Key
+			continue
+		}
+		data, _ := json.Marshal(rec)
+		items[i].key = string(data)
+	}
+	return items, nil
+}
+
+// advance loads the next page (n is the page just harvested) and reports
+// whether one was found. visited holds the signatures of the pages seen so
+// far, so a next link leading back to one of them ends pagination. Failures
+// end pagination with a warning so the pages harvested so far are kept.
+func advance(page *rod.Page, p scraper.Pagination, timeout time.Duration, n int, visited map[string]bool) bool {
+	before, err := readPageState(page)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: pagination stopped after page %d: %v\n", n, err)
+		return false
+	}
+	visited[before.Signature] = true
+
+	switch p.Mode {
+	case PaginateScroll:
+		if _, err := page.Timeout(timeout).Eval(`() => window.scrollTo(0, document.documentElement.scrollHeight)`); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: pagination stopped after page %d: %v\n", n, err)
+			return false
+		}
+	case PaginateNext:
+		res, err := page.Timeout(timeout).Eval(nextScript, p.Next)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: pagination stopped after page %d: %v\n", n, err)
+			return false
+		}
+		if !res.Value.Bool() {
+			fmt.Fprintf(os.Stderr, "[paginate] no next page after page %d\n", n)
+			return false
+		}
+	}
+
+	// Poll until the document grows (scroll) or changes (next) within the
+	// page timeout; evaluation errors while a new document loads are
+	// expected and ignored
+	wait := page.Timeout(timeout)
+	defer wait.CancelTimeout()
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-wait.GetContext().Done():
+			fmt.Fprintf(os.Stderr, "[paginate] nothing more loaded after page %d\n", n)
+			return false
+		case <-ticker.C:
+		}
+		now, err := readPageState(page)
+		if err != nil {
+			continue
+		}
+		if p.Mode == PaginateScroll && now.Height > before.Height {
+			break
+		}
+		if p.Mode == PaginateNext && now.Signature != before.Signature {
+			if visited[now.Signature] {
+				fmt.Fprintf(os.Stderr, "[paginate] page after page %d was already seen\n", n)
+				return false
+			}
+			break
+		}
+	}
+
+	// Let the new content settle before extracting it
+	_ = wait.WaitLoad()
+	wait.WaitRequestIdle(
+		networkIdleTime, nil, nil,
+		[]proto.NetworkResourceType{proto.NetworkResourceTypeImage, proto.NetworkResourceTypeMedia},
+	)()
+	return true
+}
+
+// pageState identifies the loaded content of a page
+type pageState struct {
+	Height    int    `json:"height"`
+	Signature string `json:"signature"` // hash of the URL and visible text
+}
+
+func readPageState(page *rod.Page) (pageState, error) {
+	var st pageState
+	res, err := page.Timeout(5 * time.Second).Eval(stateScript)
+	if err != nil {
+		return st, err
+	}
+	err = page.MustObjectToJSON(res).Unmarshal(&st)
+	return st, err
+}
+
+// mergePages concatenates the pages of a whole-page level into the first;
+// inventories are merged without repeats
+func mergePages(pages []Content) Content {
+	switch first := pages[0].(type) {
+	case *InventoryContent:
+		for _, p := range pages[1:] {
+			first.inventory = mergeInventories(first.inventory, p.(*InventoryContent).inventory)
+		}
+	case *PageContent:
+		for _, p := range pages[1:] {
+			p := p.(*PageContent)
+			first.htmlContent += "\n" + p.htmlContent
+			first.mainContent += "\n" + p.mainContent
+			first.textContent += "\n\n" + p.textContent
+		}
+	}
+	return pages[0]
+}
+
+// mergeInventories appends the entries of b that a does not list yet
+func mergeInventories(a, b *Inventory) *Inventory {
+	seen := map[string]bool{}
+	for _, l := range a.Links {
+		seen["link "+l.URL] = true
+	}
+	for _, img := range a.Images {
+		seen["image "+img.URL] = true
+	}
+	for _, r := range a.Scripts {
+		seen["script "+r.URL] = true
+	}
+	for _, r := range a.Stylesheets {
+		seen["stylesheet "+r.URL] = true
+	}
+	for _, r := range a.Iframes {
+		seen["iframe "+r.URL] = true
+	}
+	add := func(kind, url string) bool {
+		if seen[kind+" "+url] {
+			return false
+		}
+		seen[kind+" "+url] = true
+		return true
+	}
+
+	for _, l := range b.Links {
+		if add("link", l.URL) {
+			a.Links = append(a.Links, l)
+		}
+	}
+	for _, img := range b.Images {
+		if add("image", img.URL) {
+			a.Images = append(a.Images, img)
+		}
+	}
+	for _, r := range b.Scripts {
+		if add("script", r.URL) {
+			a.Scripts = append(a.Scripts, r)
+		}
+	}
+	for _, r := range b.Stylesheets {
+		if add("stylesheet", r.URL) {
+			a.Stylesheets = append(a.Stylesheets, r)
+		}
+	}
+	for _, r := range b.Iframes {
+		if add("iframe", r.URL) {
+			a.Iframes = append(a.Iframes, r)
+		}
+	}
+	return a
+}
+
+// itemsScript returns the HTML (or attribute or text value) and key of each
+// item matched by a CSS selector (optionally shadow-piercing) or XPath (the
+// whole document when both are empty); items without the --attr attribute
+// are left out
+const itemsScript = `(sel, xpath, pierce, key, attr, text) => {
+	const value = ` + nodeValueScript + `;
+	let nodes = [];
+	if (xpath) {
+		const res = document.evaluate(xpath, document, null, XPathResult.ORDERED_NODE_SNAPSHOT_TYPE, null);
+		for (let i = 0; i < res.snapshotLength; i++) nodes.push(res.snapshotItem(i));
+	} else if (sel) {
+		const walk = root => {
+			nodes.push(...root.querySelectorAll(sel));
+			if (pierce) root.querySelectorAll('*').forEach(e => { if (e.shadowRoot) walk(e.shadowRoot); });
+		};
+		walk(document);
+	} else {
+		nodes = [document.documentElement];
+	}
+	const items = [];
+	nodes.forEach(n => {
+		const [v, isValue] = value(n, attr, text);
+		if (v === null) return;
+		let k = '';
+		if (key && n.nodeType === Node.ELEMENT_NODE) {
+			const e = n.matches(key) ? n : n.querySelector(key);
+			if (e) k = e.href || e.textContent.trim();
+		}
+		items.push({html: v, key: k, value: isValue});
+	});
+	return items;
+}`
+
+// nextScript clicks the first visible, enabled element matching the
+// selector and reports whether there was one
+const nextScript = `sel => {
+	const el = Array.from(document.querySelectorAll(sel)).find(e =>
+		e.offsetParent !== null && getComputedStyle(e).visibility !== 'hidden' &&
+		!e.disabled && e.getAttribute('aria-disabled') !== 'true' && !e.classList.contains('disabled'));
+	if (!el) return false;
+	el.click();
+	return true;
+}`
+
+// stateScript reports the document height and a hash of URL and text
+const stateScript = `() => {
+	const t = location.href + '\n' + (document.body ? document.body.innerText : '');
+	let h = 0;
+	for (let i = 0; i < t.length; i++) h = (h * 31 + t.charCodeAt(i)) | 0;
+	return {height: document.documentElement.scrollHeight, signature: t.length + ':' + h};
+}`
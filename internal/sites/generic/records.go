@@ -0,0 +1,170 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"durl/internal/metadata"
+)
+
+// RecordsContent holds --schema or --table records instead of
+// level-extracted HTML
+type RecordsContent struct {
+	pageInfo
+	fields  []string // top-level field order used for JSON keys and CSV/Markdown columns
+	records []map[string]any
+}
+
+// NewRecordsContent creates a RecordsContent; fields is the top-level field
+// order used for JSON keys and CSV/Markdown columns
+func NewRecordsContent(fields []string, records []map[string]any, title, url string, loadTime time.Duration, response Response, meta metadata.Metadata) *RecordsContent {
+	if records == nil {
+		records = []map[string]any{}
+	}
+	return &RecordsContent{
+		pageInfo: pageInfo{
+			title:    title,
+			url:      url,
+			loadTime: loadTime,
+			response: response,
+			meta:     meta,
+		},
+		fields:  fields,
+		records: records,
+	}
+}
+
+// Records returns the records
+func (p *RecordsContent) Records() []map[string]any {
+	return p.records
+}
+
+// ToHTML renders the records as an HTML table
+func (p *RecordsContent) ToHTML() (string, error) {
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead><tr>")
+	for _, name := range p.fields {
+		sb.WriteString("<th>" + html.EscapeString(name) + "</th>")
+	}
+	sb.WriteString("</tr></thead>\n<tbody>\n")
+	for _, r := range p.records {
+		sb.WriteString("<tr>")
+		for _, cell := range p.recordRow(r) {
+			sb.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n</table>")
+	return sb.String(), nil
+}
+
+// ToText renders each record as "field: value" lines
+func (p *RecordsContent) ToText() (string, error) {
+	var sb strings.Builder
+	for i, r := range p.records {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		row := p.recordRow(r)
+		for j, name := range p.fields {
+			sb.WriteString(fmt.Sprintf("%s: %s\n", name, row[j]))
+		}
+	}
+	return sb.String(), nil
+}
+
+// ToMarkdown renders the records as a Markdown table
+func (p *RecordsContent) ToMarkdown() (string, error) {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(p.fields, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(p.fields)) + "\n")
+	for _, r := range p.records {
+		row := p.recordRow(r)
+		for i := range row {
+			row[i] = escape.Replace(row[i])
+		}
+		sb.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+	return p.withFrontMatter(sb.String()), nil
+}
+
+// ToJSON encodes the records as an array, keeping field order
+func (p *RecordsContent) ToJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, r := range p.records {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  {")
+		for j, name := range p.fields {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			key, _ := json.Marshal(name)
+			value, err := json.Marshal(r[name])
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode field %s: %w", name, err)
+			}
+			buf.WriteString("\n    ")
+			buf.Write(key)
+			buf.WriteString(": ")
+			buf.Write(value)
+		}
+		buf.WriteString("\n  }")
+	}
+	if len(p.records) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]")
+	return buf.Bytes(), nil
+}
+
+// ToCSV writes one row per record with a header of field names
+func (p *RecordsContent) ToCSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(p.fields)
+	for _, r := range p.records {
+		_ = w.Write(p.recordRow(r))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Snapshot encodes the records for the response cache
+func (p *RecordsContent) Snapshot() ([]byte, error) {
+	s := p.snapshot(kindRecords)
+	s.RecordFields = p.fields
+	s.Records = p.records
+	return json.Marshal(s)
+}
+
+// recordRow flattens a record into cells: strings as-is, numbers without
+// trailing zeros, null as empty, lists and nested records as JSON
+func (p *RecordsContent) recordRow(r map[string]any) []string {
+	row := make([]string, len(p.fields))
+	for i, name := range p.fields {
+		switch v := r[name].(type) {
+		case nil:
+		case string:
+			row[i] = v
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			b, _ := json.Marshal(v)
+			row[i] = string(b)
+		}
+	}
+	return row
+}
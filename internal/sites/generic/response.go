@@ -0,0 +1,127 @@
+package generic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Response describes the HTTP response of the main document
+type Response struct {
+	StatusCode  int               // HTTP status code (0 if no response was seen)
+	StatusText  string            // HTTP status text
+	Protocol    string            // Negotiated protocol (http/1.1, h2, ...)
+	Headers     map[string]string // Response headers
+	ContentType string            // Content-Type header, or MIME type reported by the browser
+	Redirects   []Redirect        // Redirect hops before the final response
+}
+
+// Redirect is one hop of the main document redirect chain
+type Redirect struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status"`
+	Location   string `json:"location"`
+}
+
+// HeaderText formats the status line and headers the way curl -i prints them
+func (r Response) HeaderText() string {
+	var sb strings.Builder
+
+	version := "HTTP/1.1"
+	switch strings.ToLower(r.Protocol) {
+	case "h2":
+		version = "HTTP/2"
+	case "h3", "h3-29":
+		version = "HTTP/3"
+	case "http/1.0":
+		version = "HTTP/1.0"
+	}
+	sb.WriteString(strings.TrimSpace(fmt.Sprintf("%s %d %s", version, r.StatusCode, r.StatusText)))
+	sb.WriteString("\n")
+
+	names := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		// CDP joins repeated headers with newlines
+		for _, v := range strings.Split(r.Headers[k], "\n") {
+			sb.WriteString(k + ": " + v + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// responseRecorder captures the main document response and its redirect
+// chain from CDP Network events while a page navigates
+type responseRecorder struct {
+	mu        sync.Mutex
+	frameID   proto.PageFrameID
+	requestID proto.NetworkRequestID
+	resp      Response
+	cancel    context.CancelFunc
+}
+
+// recordResponse starts listening for main-frame document events on page.
+// Call stop to end recording and obtain the captured response.
+func recordResponse(page *rod.Page) *responseRecorder {
+	ctx, cancel := context.WithCancel(page.GetContext())
+	r := &responseRecorder{frameID: page.FrameID, cancel: cancel}
+
+	wait := page.Context(ctx).EachEvent(
+		func(e *proto.NetworkRequestWillBeSent) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != r.frameID {
+				return
+			}
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if e.RedirectResponse != nil && e.RequestID == r.requestID {
+				r.resp.Redirects = append(r.resp.Redirects, Redirect{
+					URL:        e.RedirectResponse.URL,
+					StatusCode: e.RedirectResponse.Status,
+					Location:   e.Request.URL,
+				})
+			}
+			r.requestID = e.RequestID
+		},
+		func(e *proto.NetworkResponseReceived) {
+			if e.Type != proto.NetworkResourceTypeDocument || e.FrameID != r.frameID {
+				return
+			}
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if e.RequestID != r.requestID {
+				return
+			}
+			r.resp.StatusCode = e.Response.Status
+			r.resp.StatusText = e.Response.StatusText
+			r.resp.Protocol = e.Response.Protocol
+			r.resp.Headers = make(map[string]string, len(e.Response.Headers))
+			r.resp.ContentType = e.Response.MIMEType
+			for k, v := range e.Response.Headers {
+				r.resp.Headers[k] = v.String()
+				if strings.EqualFold(k, "content-type") {
+					r.resp.ContentType = v.String()
+				}
+			}
+		},
+	)
+	go wait()
+
+	return r
+}
+
+// stop ends recording and returns the captured response
+func (r *responseRecorder) stop() Response {
+	r.cancel()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.resp
+}
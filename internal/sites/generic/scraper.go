@@ -3,11 +3,15 @@ package generic
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"durl/internal/browser"
+	"durl/internal/metadata"
+	"durl/internal/readability"
 	"durl/internal/scraper"
-	"github.com/go-rod/rod/lib/proto"
+	"durl/internal/table"
 )
 
 // GenericScraper generic scraper
@@ -29,38 +33,176 @@ func (g *GenericScraper) Name() string {
 // PageContent is returned with pre-extracted strings so it does not require
 // a live browser connection during formatting.
 func (g *GenericScraper) Scrape(ctx context.Context, target string, opts scraper.Options) (scraper.Content, error) {
-	b, err := browser.New(g.cfg)
+	b, err := opts.Pool.Acquire(g.cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser: %w", err)
 	}
 	defer b.Close()
 
+	if err := opts.Login.Run(b, opts.Timeout); err != nil {
+		return nil, err
+	}
+
+	wait, err := WaitSteps(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	f := NewFetcher(b)
-	result, err := f.Fetch(target, opts.Method, opts.Headers, opts.Body, WaitStrategy(opts.WaitFor), opts.WaitTarget, opts.Timeout)
+	f.SetActions(opts.Actions)
+	result, err := f.Fetch(target, opts.Method, opts.Headers, opts.Body, wait, opts.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch: %w", err)
 	}
 	defer result.Page.Close()
 
-	// When the default "load" wait strategy is used, additionally wait for
-	// network idle so that JS-driven pages (e.g. Bing/Google SPA search) finish
-	// populating dynamic content before we extract.
-	if opts.WaitFor == "load" {
-		wait := result.Page.Timeout(opts.Timeout).WaitRequestIdle(
-			500*time.Millisecond, nil, nil,
-			[]proto.NetworkResourceType{proto.NetworkResourceTypeImage, proto.NetworkResourceTypeMedia},
-		)
-		wait()
+	return ExtractPage(result, opts)
+}
+
+// Restore rebuilds cached content
+func (g *GenericScraper) Restore(data []byte) (scraper.Content, error) {
+	return RestoreContent(data)
+}
+
+// ExtractPage extracts a fetched page at the requested level into a
+// Content. The caller keeps ownership of
+// result.Page and must close it.
+func ExtractPage(result *FetchResult, opts scraper.Options) (Content, error) {
+	var err error
+
+	// Challenge pages are always reported; with --fail they and HTTP error
+	// statuses abort before extraction
+	if reason := browser.DetectChallenge(result.Page); reason != "" {
+		if opts.Fail {
+			return nil, fmt.Errorf("%w: %s", scraper.ErrBlocked, reason)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v: %s\n", scraper.ErrBlocked, reason)
+	}
+	if opts.Fail {
+		if result.Response.StatusCode >= 400 {
+			return nil, &scraper.HTTPError{
+				URL:        result.URL,
+				StatusCode: result.Response.StatusCode,
+				StatusText: result.Response.StatusText,
+			}
+		}
 	}
 
 	// Extract all content while the browser is still open.
 	// PageContent must not hold a live page reference because the browser is
 	// closed (via defer b.Close()) before the formatter calls ToHTML/ToMarkdown/etc.
 	extractor := NewExtractor(result.Page)
+	extractor.SetScope(opts.Pierce, opts.Frames)
+
+	// Capture before extraction so screenshots and archives see the page as loaded
+	var capture []byte
+	if opts.Capture.Format != "" {
+		capture, err = capturePage(result.Page, opts.Capture, opts.Level, opts.Selector, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	finish := func(content Content) Content {
+		content.SetFrontMatter(opts.FrontMatter)
+		if capture != nil {
+			content.SetCapture(opts.Capture.Format, capture)
+		}
+		return content
+	}
+
+	var meta metadata.Metadata
+	if doc, err := result.Page.Timeout(10 * time.Second).HTML(); err == nil {
+		meta = metadata.Extract(doc, result.URL)
+		extractor.SetDocument(doc, meta)
+	}
+
+	var content Content
+	if opts.Pagination.Mode != "" {
+		content, err = paginate(result, extractor, opts, meta)
+	} else {
+		content, err = extractLevel(extractor, result, opts, meta)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if opts.Table != "" {
+		if content, err = selectTable(content, opts); err != nil {
+			return nil, err
+		}
+	}
+	return finish(content), nil
+}
+
+// selectTable replaces the extracted content with the rows of the --table
+// it selects, one record per row keyed by the flattened column headers
+func selectTable(content Content, opts scraper.Options) (Content, error) {
+	var tables []*table.Table
+	if pc, ok := content.(*PageContent); ok {
+		var err error
+		if tables, err = table.ParseAll(pc.mainContent); err != nil {
+			return nil, err
+		}
+	}
+	t, err := table.Select(tables, opts.Table)
+	if err != nil {
+		if opts.Fail {
+			return nil, fmt.Errorf("%w: %v", scraper.ErrSelectorNotFound, err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		t = &table.Table{}
+	}
+	headers := t.Headers
+	if headers == nil {
+		headers = []string{}
+	}
+	info := content.info()
+	return NewRecordsContent(headers, t.Records(), info.title, info.url, info.loadTime, info.response, info.meta), nil
+}
+
+// extractLevel extracts the page as it is now: schema records, the link
+// inventory or content at the requested level
+func extractLevel(extractor *Extractor, result *FetchResult, opts scraper.Options, meta metadata.Metadata) (Content, error) {
+	var err error
+
+	// --schema replaces level extraction with structured records
+	if opts.Schema != nil {
+		records, err := extractor.ExtractSchema(opts.Schema, result.URL)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Fail && len(records) == 0 {
+			return nil, fmt.Errorf("%w: schema matched no records", scraper.ErrSelectorNotFound)
+		}
+		return NewRecordsContent(opts.Schema.FieldNames(), records, result.Title, result.URL, result.LoadTime, result.Response, meta), nil
+	}
+
+	if opts.Level == "links" {
+		inv, err := extractor.Inventory(result.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewInventoryContent(inv, result.Title, result.URL, result.LoadTime, result.Response, meta), nil
+	}
 
 	var htmlContent, mainContent, textContent string
 
-	if opts.Level == "body" {
+	if opts.Attr != "" || opts.Text || opts.Level == "xpath" {
+		// --attr/--text and XPath results that are not elements give one
+		// value per match; XPath element matches stay HTML
+		matches, values, err := extractor.Select(opts.Selector, opts.Level == "xpath", opts.Attr, opts.Text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract content: %w", err)
+		}
+		if opts.Fail && len(matches) == 0 {
+			return nil, fmt.Errorf("%w: %s", scraper.ErrSelectorNotFound, opts.Selector)
+		}
+		if values {
+			return NewValuesContent(matches, result.Title, result.URL, result.LoadTime, result.Response, meta), nil
+		}
+		mainContent = strings.Join(matches, "\n")
+		htmlContent = mainContent
+		textContent = mainContent
+	} else if opts.Level == "body" {
 		// ToHTML() needs body innerHTML; ToText() needs body innerText
 		htmlContent, err = extractor.Extract("html", "")
 		if err != nil {
@@ -83,5 +225,25 @@ func (g *GenericScraper) Scrape(ctx context.Context, target string, opts scraper
 		textContent = mainContent
 	}
 
-	return NewPageContent(htmlContent, mainContent, textContent, opts.Level, result.Title, result.URL, result.LoadTime), nil
+	if opts.Fail && (opts.Level == "css" || opts.Level == "xpath") && mainContent == "" {
+		return nil, fmt.Errorf("%w: %s", scraper.ErrSelectorNotFound, opts.Selector)
+	}
+
+	var article readability.Metadata
+	if a := extractor.Article(); a != nil {
+		article = a.Metadata
+	}
+
+	return NewPageContent(PageOptions{
+		HTML:     htmlContent,
+		Main:     mainContent,
+		Text:     textContent,
+		Level:    opts.Level,
+		Title:    result.Title,
+		URL:      result.URL,
+		LoadTime: result.LoadTime,
+		Response: result.Response,
+		Article:  article,
+		Meta:     meta,
+	}), nil
 }
@@ -0,0 +1,83 @@
+package generic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"durl/internal/metadata"
+)
+
+// ValuesContent holds one value per selector match (--attr, --text, or
+// XPath attribute, text and string results) instead of element HTML
+type ValuesContent struct {
+	pageInfo
+	values []string
+}
+
+// NewValuesContent creates a ValuesContent from the selector values
+func NewValuesContent(values []string, title, url string, loadTime time.Duration, response Response, meta metadata.Metadata) *ValuesContent {
+	if values == nil {
+		values = []string{}
+	}
+	return &ValuesContent{
+		pageInfo: pageInfo{
+			title:    title,
+			url:      url,
+			loadTime: loadTime,
+			response: response,
+			meta:     meta,
+		},
+		values: values,
+	}
+}
+
+// Values returns the selector values
+func (p *ValuesContent) Values() []string {
+	return p.values
+}
+
+// ToHTML lists the values one per line
+func (p *ValuesContent) ToHTML() (string, error) {
+	return p.ToText()
+}
+
+// ToText lists the values one per line
+func (p *ValuesContent) ToText() (string, error) {
+	return strings.Join(p.values, "\n"), nil
+}
+
+// ToMarkdown lists the values one per line
+func (p *ValuesContent) ToMarkdown() (string, error) {
+	return p.withFrontMatter(strings.Join(p.values, "\n")), nil
+}
+
+// ToJSON encodes the values as an array of strings
+func (p *ValuesContent) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(p.values, "", "  ")
+}
+
+// ToCSV writes one row per value under a "value" header
+func (p *ValuesContent) ToCSV() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"value"})
+	for _, v := range p.values {
+		_ = w.Write([]string{v})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Snapshot encodes the values for the response cache
+func (p *ValuesContent) Snapshot() ([]byte, error) {
+	s := p.snapshot(kindValues)
+	s.Values = p.values
+	return json.Marshal(s)
+}
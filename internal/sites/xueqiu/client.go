@@ -7,11 +7,15 @@ import (
 	"time"
 
 	"durl/internal/browser"
+	"durl/internal/scraper"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
 )
 
+// sessionCookie is the token cookie xueqiu sets on the first homepage visit
+const sessionCookie = "xq_a_token"
+
 // Discussion discussion structure
 type Discussion struct {
 	ID           string
@@ -65,8 +69,11 @@ func (c *Client) Init(timeout time.Duration) error {
 	})
 	_, _ = page.EvalOnNewDocument(`Object.defineProperty(navigator, 'webdriver', {get: () => undefined});`)
 
-	_ = page.Timeout(timeout).Navigate("https://xueqiu.com")
-	time.Sleep(4 * time.Second)
+	// A session restored from a cookie file or profile skips the homepage warm-up
+	if !c.browser.HasCookie("xueqiu.com", sessionCookie) {
+		_ = page.Timeout(timeout).Navigate("https://xueqiu.com")
+		time.Sleep(4 * time.Second)
+	}
 
 	if _, err := page.Timeout(10 * time.Second).Eval(`() => document.title`); err != nil {
 		return fmt.Errorf("page not available: %w", err)
@@ -92,6 +99,9 @@ func (c *Client) FetchByURL(targetURL string, cutoff time.Time, sort string, max
 	time.Sleep(5 * time.Second)
 
 	if _, err := c.page.Timeout(15 * time.Second).Element(".timeline__item"); err != nil {
+		if reason := browser.DetectChallenge(c.page); reason != "" {
+			return nil, fmt.Errorf("%w: %s", scraper.ErrBlocked, reason)
+		}
 		return nil, fmt.Errorf("timeline not found on page: %w", err)
 	}
 
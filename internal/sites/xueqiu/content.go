@@ -103,3 +103,15 @@ func (x *XueqiuContent) ToCSV() (string, error) {
 	w.Flush()
 	return buf.String(), nil
 }
+
+// discussionSnapshot is the cached form of a XueqiuContent
+type discussionSnapshot struct {
+	Discussions []Discussion `json:"discussions"`
+	Title       string       `json:"title"`
+	Cutoff      time.Time    `json:"cutoff"`
+}
+
+// Snapshot encodes the discussions for the response cache
+func (x *XueqiuContent) Snapshot() ([]byte, error) {
+	return json.Marshal(discussionSnapshot{Discussions: x.discussions, Title: x.title, Cutoff: x.cutoff})
+}
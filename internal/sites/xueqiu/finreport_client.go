@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"durl/internal/browser"
+	"durl/internal/scraper"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/proto"
@@ -62,8 +63,10 @@ func (c *FinReportClient) initPage(timeout time.Duration) (*pageWorker, error) {
 	})
 	_, _ = page.EvalOnNewDocument(`Object.defineProperty(navigator, 'webdriver', {get: () => undefined});`)
 
-	_ = page.Timeout(timeout).Navigate("https://xueqiu.com")
-	time.Sleep(4 * time.Second)
+	if !c.browser.HasCookie("xueqiu.com", sessionCookie) {
+		_ = page.Timeout(timeout).Navigate("https://xueqiu.com")
+		time.Sleep(4 * time.Second)
+	}
 
 	if _, err := page.Timeout(10 * time.Second).Eval(`() => document.title`); err != nil {
 		_ = page.Close()
@@ -129,6 +132,9 @@ func (w *pageWorker) fetchOneReport(code, reportID, reportName string, timeout t
 		return FinReportTable{}, fmt.Errorf("navigate to %s: %w", targetURL, err)
 	}
 	if _, err := w.page.Timeout(timeout).Element(".stock-info-content"); err != nil {
+		if reason := browser.DetectChallenge(w.page); reason != "" {
+			return FinReportTable{}, fmt.Errorf("%w: %s", scraper.ErrBlocked, reason)
+		}
 		return FinReportTable{}, fmt.Errorf(".stock-info-content not found for %s: %w", reportName, err)
 	}
 	time.Sleep(2 * time.Second)
@@ -160,3 +160,15 @@ func (f *FinReportContent) ToJSON() ([]byte, error) {
 		Tables:    f.tables,
 	})
 }
+
+// finReportSnapshot is the cached form of a FinReportContent
+type finReportSnapshot struct {
+	StockCode string           `json:"stock_code"`
+	StockName string           `json:"stock_name"`
+	Tables    []FinReportTable `json:"tables"`
+}
+
+// Snapshot encodes the report tables for the response cache
+func (f *FinReportContent) Snapshot() ([]byte, error) {
+	return json.Marshal(finReportSnapshot{StockCode: f.stockCode, StockName: f.stockName, Tables: f.tables})
+}
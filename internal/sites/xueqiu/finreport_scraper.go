@@ -2,10 +2,11 @@ package xueqiu
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
-	"durl/internal/browser"
 	"durl/internal/scraper"
 )
 
@@ -21,12 +22,24 @@ func (x *XueqiuFinReportScraper) Name() string {
 	return "xueqiu.finreport"
 }
 
+// RequestURL returns the report page for target, or the stock search page
+// when target is a name that must be resolved first
+func (x *XueqiuFinReportScraper) RequestURL(target string) string {
+	if regexp.MustCompile(`xueqiu\.com`).MatchString(target) {
+		if !strings.HasPrefix(target, "http") {
+			return "https://" + target
+		}
+		return target
+	}
+	if code, ok := stockCode(target); ok {
+		return "https://xueqiu.com/snowman/S/" + code + "/detail"
+	}
+	return stockSearchURL(strings.TrimSpace(target))
+}
+
 // Scrape executes financial report scraping
 func (x *XueqiuFinReportScraper) Scrape(ctx context.Context, target string, opts scraper.Options) (scraper.Content, error) {
-	b, err := browser.New(browser.Config{
-		ProxyURL: opts.ProxyURL,
-		Headless: !opts.ShowUI,
-	})
+	b, err := opts.Pool.Acquire(opts.BrowserConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser: %w", err)
 	}
@@ -64,3 +77,12 @@ func (x *XueqiuFinReportScraper) Scrape(ctx context.Context, target string, opts
 
 	return NewFinReportContent(code, stockName, tables), nil
 }
+
+// Restore rebuilds cached content
+func (x *XueqiuFinReportScraper) Restore(data []byte) (scraper.Content, error) {
+	var snap finReportSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode cached report: %w", err)
+	}
+	return NewFinReportContent(snap.StockCode, snap.StockName, snap.Tables), nil
+}
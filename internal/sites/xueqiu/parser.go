@@ -18,11 +18,20 @@ import (
 // 3. 2-5 digit pure number → pad to 5 digits
 // 4. Others → call searchStockByPage to search
 func ResolveStockCode(query string, page *rod.Page) (code, name string, err error) {
+	if code, ok := stockCode(query); ok {
+		return code, "", nil
+	}
+	return searchStockByPage(page, strings.TrimSpace(query))
+}
+
+// stockCode recognizes queries that already are stock codes: SZ/SH/HK
+// prefixed, 6-digit A-share or 2-5 digit HK codes
+func stockCode(query string) (string, bool) {
 	query = strings.TrimSpace(query)
 	upper := strings.ToUpper(query)
 
 	if regexp.MustCompile(`^(SZ|SH|HK)\d+$`).MatchString(upper) {
-		return upper, "", nil
+		return upper, true
 	}
 
 	if regexp.MustCompile(`^\d{6}$`).MatchString(query) {
@@ -31,20 +40,24 @@ func ResolveStockCode(query string, page *rod.Page) (code, name string, err erro
 		if ch == '0' || ch == '3' || ch == '1' {
 			prefix = "SZ"
 		}
-		return prefix + query, "", nil
+		return prefix + query, true
 	}
 
 	if regexp.MustCompile(`^\d{2,5}$`).MatchString(query) {
-		padded := fmt.Sprintf("%05s", query)
-		return padded, "", nil
+		return fmt.Sprintf("%05s", query), true
 	}
 
-	return searchStockByPage(page, query)
+	return "", false
+}
+
+// stockSearchURL returns the Xueqiu search page for a stock name query
+func stockSearchURL(query string) string {
+	return "https://xueqiu.com/k?q=" + url.QueryEscape(query)
 }
 
 // searchStockByPage searches stock code by page (private function)
 func searchStockByPage(page *rod.Page, query string) (string, string, error) {
-	searchURL := "https://xueqiu.com/k?q=" + url.QueryEscape(query)
+	searchURL := stockSearchURL(query)
 
 	if err := page.Timeout(20 * time.Second).Navigate(searchURL); err != nil {
 		return "", "", fmt.Errorf("failed to navigate to search: %w", err)
@@ -2,11 +2,11 @@ package xueqiu
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
 
-	"durl/internal/browser"
 	"durl/internal/scraper"
 )
 
@@ -22,6 +22,21 @@ func (x *XueqiuScraper) Name() string {
 	return "xueqiu.comment"
 }
 
+// RequestURL returns the discussion page for target, or the stock search
+// page when target is a name that must be resolved first
+func (x *XueqiuScraper) RequestURL(target string) string {
+	if strings.Contains(target, "xueqiu.com") {
+		if !strings.HasPrefix(target, "http") {
+			return "https://" + target
+		}
+		return target
+	}
+	if code, ok := stockCode(target); ok {
+		return "https://xueqiu.com/S/" + code
+	}
+	return stockSearchURL(strings.TrimSpace(target))
+}
+
 // Scrape executes Xueqiu scraping
 func (x *XueqiuScraper) Scrape(ctx context.Context, target string, opts scraper.Options) (scraper.Content, error) {
 	// Read parameters from opts.Extra
@@ -47,10 +62,7 @@ func (x *XueqiuScraper) Scrape(ctx context.Context, target string, opts scraper.
 	}
 
 	// Create browser
-	b, err := browser.New(browser.Config{
-		ProxyURL: opts.ProxyURL,
-		Headless: !opts.ShowUI,
-	})
+	b, err := opts.Pool.Acquire(opts.BrowserConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create browser: %w", err)
 	}
@@ -94,3 +106,12 @@ func (x *XueqiuScraper) Scrape(ctx context.Context, target string, opts scraper.
 
 	return NewXueqiuContent(discussions, title, cutoff), nil
 }
+
+// Restore rebuilds cached content
+func (x *XueqiuScraper) Restore(data []byte) (scraper.Content, error) {
+	var snap discussionSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("failed to decode cached discussions: %w", err)
+	}
+	return NewXueqiuContent(snap.Discussions, snap.Title, snap.Cutoff), nil
+}
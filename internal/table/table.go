@@ -0,0 +1,266 @@
+// Package table reads HTML tables into a rectangular grid: rowspan and
+// colspan are expanded, header rows are detected and multi-level headers
+// are flattened into one column name per column.
+package table
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Span limits as browsers apply them, so a bogus attribute cannot allocate
+// a huge grid
+const (
+	maxColspan = 1000
+	maxRowspan = 65534
+)
+
+// Table is one HTML table as a grid
+type Table struct {
+	Caption string     // <caption> text
+	Headers []string   // one unique, non-empty name per column
+	Rows    [][]string // body rows, each as wide as Headers; merged cells repeat their text
+}
+
+// cell is one grid slot; slots covered by a span share the cell
+type cell struct {
+	text   string
+	header bool // <th>
+}
+
+// ParseAll parses every table in an HTML fragment, in document order;
+// nested tables are parsed separately and left out of their parent's cells
+func ParseAll(html string) ([]*Table, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	var tables []*Table
+	doc.Find("table").Each(func(_ int, s *goquery.Selection) {
+		if t := Parse(s); t != nil {
+			tables = append(tables, t)
+		}
+	})
+	return tables, nil
+}
+
+// Parse reads a <table> selection; it returns nil for a table without cells
+func Parse(table *goquery.Selection) *Table {
+	// Rows of nested tables belong to those tables
+	rows := table.Find("tr").FilterFunction(func(_ int, tr *goquery.Selection) bool {
+		return tr.Closest("table").IsSelection(table)
+	})
+
+	var grid [][]*cell
+	theadRows := 0
+	rows.Each(func(r int, tr *goquery.Selection) {
+		if goquery.NodeName(tr.Parent()) == "thead" && theadRows == r {
+			theadRows++
+		}
+		for len(grid) <= r {
+			grid = append(grid, nil)
+		}
+		col := 0
+		tr.ChildrenFiltered("th, td").Each(func(_ int, td *goquery.Selection) {
+			c := &cell{text: cellText(td), header: goquery.NodeName(td) == "th"}
+			colspan := max(span(td, "colspan", 1, maxColspan), 1)
+			// rowspan="0" spans the remaining rows
+			rowspan := span(td, "rowspan", 1, maxRowspan)
+			if rowspan == 0 || r+rowspan > rows.Length() {
+				rowspan = rows.Length() - r
+			}
+
+			for col < len(grid[r]) && grid[r][col] != nil {
+				col++
+			}
+			for dr := 0; dr < rowspan; dr++ {
+				for len(grid) <= r+dr {
+					grid = append(grid, nil)
+				}
+				for dc := 0; dc < colspan; dc++ {
+					row := grid[r+dr]
+					for len(row) <= col+dc {
+						row = append(row, nil)
+					}
+					row[col+dc] = c
+					grid[r+dr] = row
+				}
+			}
+			col += colspan
+		})
+	})
+
+	width := 0
+	for _, row := range grid {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return nil
+	}
+
+	t := &Table{Caption: cellText(table.ChildrenFiltered("caption").First())}
+	headerRows := detectHeaderRows(grid, theadRows)
+	t.Headers = flattenHeaders(grid[:headerRows], width)
+	for _, row := range grid[headerRows:] {
+		values := make([]string, width)
+		empty := true
+		for i, c := range row {
+			if c != nil {
+				values[i] = c.text
+				empty = empty && c.text == ""
+			}
+		}
+		if !empty {
+			t.Rows = append(t.Rows, values)
+		}
+	}
+	return t
+}
+
+// detectHeaderRows returns the number of leading header rows: the <thead>
+// rows, else the leading rows made only of <th> cells (keeping at least one
+// body row). Tables with neither have no header rows, so every row stays
+// data and the columns are named "Column N".
+func detectHeaderRows(grid [][]*cell, theadRows int) int {
+	if theadRows > 0 {
+		return theadRows
+	}
+	n := 0
+	for n < len(grid)-1 && allHeaders(grid[n]) {
+		n++
+	}
+	return n
+}
+
+func allHeaders(row []*cell) bool {
+	if len(row) == 0 {
+		return false
+	}
+	for _, c := range row {
+		if c != nil && !c.header {
+			return false
+		}
+	}
+	return true
+}
+
+// flattenHeaders joins the header texts above each column with " / ",
+// skipping repeats from spanned cells; empty names become "Column N" and
+// duplicates get a " (2)", " (3)", ... suffix
+func flattenHeaders(rows [][]*cell, width int) []string {
+	headers := make([]string, width)
+	seen := map[string]int{}
+	for col := range headers {
+		var parts []string
+		for _, row := range rows {
+			if col >= len(row) || row[col] == nil || row[col].text == "" {
+				continue
+			}
+			if n := len(parts); n == 0 || parts[n-1] != row[col].text {
+				parts = append(parts, row[col].text)
+			}
+		}
+		name := strings.Join(parts, " / ")
+		if name == "" {
+			name = "Column " + strconv.Itoa(col+1)
+		}
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			name = fmt.Sprintf("%s (%d)", name, n)
+		}
+		headers[col] = name
+	}
+	return headers
+}
+
+// Records returns one record per row keyed by column name
+func (t *Table) Records() []map[string]any {
+	records := make([]map[string]any, len(t.Rows))
+	for i, row := range t.Rows {
+		r := make(map[string]any, len(row))
+		for j, v := range row {
+			r[t.Headers[j]] = v
+		}
+		records[i] = r
+	}
+	return records
+}
+
+// Markdown renders the table as a Markdown table
+func (t *Table) Markdown() string {
+	escape := strings.NewReplacer("|", `\|`)
+	var sb strings.Builder
+	if t.Caption != "" {
+		sb.WriteString(t.Caption + "\n\n")
+	}
+	row := func(cells []string) {
+		escaped := make([]string, len(cells))
+		for i, c := range cells {
+			escaped[i] = escape.Replace(c)
+		}
+		sb.WriteString("| " + strings.Join(escaped, " | ") + " |\n")
+	}
+	row(t.Headers)
+	sb.WriteString("|" + strings.Repeat(" --- |", len(t.Headers)) + "\n")
+	for _, r := range t.Rows {
+		row(r)
+	}
+	return sb.String()
+}
+
+// WriteCSV writes the header row and the body rows
+func (t *Table) WriteCSV(w *csv.Writer) error {
+	if err := w.Write(t.Headers); err != nil {
+		return err
+	}
+	for _, r := range t.Rows {
+		if err := w.Write(r); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Select picks a table by 1-based index or by caption, matched
+// case-insensitively as a substring
+func Select(tables []*Table, spec string) (*Table, error) {
+	if n, err := strconv.Atoi(spec); err == nil {
+		if n < 1 || n > len(tables) {
+			return nil, fmt.Errorf("table %d not found (page has %d table(s))", n, len(tables))
+		}
+		return tables[n-1], nil
+	}
+	want := strings.ToLower(spec)
+	for _, t := range tables {
+		if t.Caption != "" && strings.Contains(strings.ToLower(t.Caption), want) {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("no table with caption %q (page has %d table(s))", spec, len(tables))
+}
+
+// cellText is the text of a cell with whitespace collapsed and nested
+// tables left out
+func cellText(s *goquery.Selection) string {
+	if s.Length() == 0 {
+		return ""
+	}
+	s = s.Clone()
+	s.Find("table").Remove()
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+// span reads a colspan/rowspan attribute, falling back to def when it is
+// missing or invalid and capping it at limit
+func span(s *goquery.Selection, attr string, def, limit int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s.AttrOr(attr, "")))
+	if err != nil || n < 0 {
+		return def
+	}
+	return min(n, limit)
+}
@@ -0,0 +1,191 @@
+package table
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseOne(t *testing.T, html string) *Table {
+	t.Helper()
+	tables, err := ParseAll(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) == 0 {
+		t.Fatal("no table parsed")
+	}
+	return tables[0]
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		headers []string
+		rows    [][]string
+	}{
+		{
+			name: "thead rows are headers",
+			html: `<table><thead><tr><td>Name</td><td>Price</td></tr></thead>
+				<tbody><tr><td>A</td><td>1</td></tr><tr><td>B</td><td>2</td></tr></tbody></table>`,
+			headers: []string{"Name", "Price"},
+			rows:    [][]string{{"A", "1"}, {"B", "2"}},
+		},
+		{
+			name:    "leading th rows are headers",
+			html:    `<table><tr><th>Name</th><th>Price</th></tr><tr><td>A</td><td>1</td></tr></table>`,
+			headers: []string{"Name", "Price"},
+			rows:    [][]string{{"A", "1"}},
+		},
+		{
+			name:    "row headers do not make a header row",
+			html:    `<table><tr><th>A</th><td>1</td></tr><tr><th>B</th><td>2</td></tr></table>`,
+			headers: []string{"Column 1", "Column 2"},
+			rows:    [][]string{{"A", "1"}, {"B", "2"}},
+		},
+		{
+			name:    "header-less table keeps its first row",
+			html:    `<table><tr><td>A</td><td>1</td></tr><tr><td>B</td><td>2</td></tr></table>`,
+			headers: []string{"Column 1", "Column 2"},
+			rows:    [][]string{{"A", "1"}, {"B", "2"}},
+		},
+		{
+			name:    "single th row stays data",
+			html:    `<table><tr><th>Only</th></tr></table>`,
+			headers: []string{"Column 1"},
+			rows:    [][]string{{"Only"}},
+		},
+		{
+			name: "rowspan repeats down",
+			html: `<table><tr><th>Group</th><th>Item</th></tr>
+				<tr><td rowspan="2">G1</td><td>a</td></tr><tr><td>b</td></tr></table>`,
+			headers: []string{"Group", "Item"},
+			rows:    [][]string{{"G1", "a"}, {"G1", "b"}},
+		},
+		{
+			name: "rowspan 0 spans the remaining rows",
+			html: `<table><tr><th>Group</th><th>Item</th></tr>
+				<tr><td rowspan="0">G</td><td>a</td></tr><tr><td>b</td></tr><tr><td>c</td></tr></table>`,
+			headers: []string{"Group", "Item"},
+			rows:    [][]string{{"G", "a"}, {"G", "b"}, {"G", "c"}},
+		},
+		{
+			name: "rowspan past the last row is clipped",
+			html: `<table><tr><th>A</th><th>B</th></tr>
+				<tr><td rowspan="9">x</td><td>1</td></tr></table>`,
+			headers: []string{"A", "B"},
+			rows:    [][]string{{"x", "1"}},
+		},
+		{
+			name: "colspan repeats across",
+			html: `<table><tr><th colspan="2">Both</th></tr>
+				<tr><td>1</td><td>2</td></tr></table>`,
+			headers: []string{"Both", "Both (2)"},
+			rows:    [][]string{{"1", "2"}},
+		},
+		{
+			name: "colspan 0 and invalid spans count as 1",
+			html: `<table><tr><th colspan="0">A</th><th colspan="x">B</th><th rowspan="-1">C</th></tr>
+				<tr><td>1</td><td>2</td><td>3</td></tr></table>`,
+			headers: []string{"A", "B", "C"},
+			rows:    [][]string{{"1", "2", "3"}},
+		},
+		{
+			name: "multi-level headers are flattened",
+			html: `<table><thead>
+				<tr><th rowspan="2">Item</th><th colspan="2">2024</th></tr>
+				<tr><th>Q1</th><th>Q2</th></tr>
+				</thead><tbody><tr><td>Sales</td><td>1</td><td>2</td></tr></tbody></table>`,
+			headers: []string{"Item", "2024 / Q1", "2024 / Q2"},
+			rows:    [][]string{{"Sales", "1", "2"}},
+		},
+		{
+			name: "ragged rows are padded and empty rows dropped",
+			html: `<table><tr><th>A</th><th>B</th><th>C</th></tr>
+				<tr><td>1</td></tr><tr><td></td><td> </td></tr><tr><td>2</td><td>3</td></tr></table>`,
+			headers: []string{"A", "B", "C"},
+			rows:    [][]string{{"1", "", ""}, {"2", "3", ""}},
+		},
+		{
+			name:    "nested tables stay out of cells",
+			html:    `<table><tr><th>Outer</th></tr><tr><td>text<table><tr><td>inner</td></tr></table></td></tr></table>`,
+			headers: []string{"Outer"},
+			rows:    [][]string{{"text"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseOne(t, tt.html)
+			if !reflect.DeepEqual(got.Headers, tt.headers) {
+				t.Errorf("Headers = %q, want %q", got.Headers, tt.headers)
+			}
+			if !reflect.DeepEqual(got.Rows, tt.rows) {
+				t.Errorf("Rows = %q, want %q", got.Rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestColspanOverflowIsCapped(t *testing.T) {
+	got := parseOne(t, `<table><tr><td colspan="999999">x</td></tr></table>`)
+	if len(got.Headers) != maxColspan {
+		t.Errorf("width = %d, want %d", len(got.Headers), maxColspan)
+	}
+}
+
+func TestParseAllSkipsEmptyTables(t *testing.T) {
+	tables, err := ParseAll(`<table></table><table><caption> Prices </caption><tr><td>1</td></tr></table>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Caption != "Prices" {
+		t.Fatalf("got %d tables, want the captioned one", len(tables))
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tables := []*Table{{Caption: "Balance Sheet"}, {Caption: "Quarterly Results"}, {}}
+	tests := []struct {
+		spec    string
+		want    *Table
+		wantErr bool
+	}{
+		{spec: "1", want: tables[0]},
+		{spec: "3", want: tables[2]},
+		{spec: "0", wantErr: true},
+		{spec: "4", wantErr: true},
+		{spec: "quarterly", want: tables[1]},
+		{spec: "SHEET", want: tables[0]},
+		{spec: "cash flow", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Select(tables, tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Select(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Select(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestMarkdownEscapesPipes(t *testing.T) {
+	tbl := &Table{Headers: []string{"a|b"}, Rows: [][]string{{"1|2"}}}
+	want := "| a\\|b |\n| --- |\n| 1\\|2 |\n"
+	if got := tbl.Markdown(); got != want {
+		t.Errorf("Markdown() = %q, want %q", got, want)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	tbl := parseOne(t, `<table><tr><th>k</th><th>v</th></tr><tr><td>a</td><td>1</td></tr></table>`)
+	want := []map[string]any{{"k": "a", "v": "1"}}
+	if got := tbl.Records(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Records() = %v, want %v", got, want)
+	}
+	if s := tbl.Markdown(); !strings.HasPrefix(s, "| k | v |") {
+		t.Errorf("Markdown() = %q", s)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,14 +10,21 @@ import (
 	"strings"
 	"time"
 
+	"durl/internal/actions"
 	"durl/internal/browser"
+	"durl/internal/cache"
 	"durl/internal/formatter"
+	"durl/internal/har"
+	"durl/internal/login"
+	"durl/internal/politeness"
+	"durl/internal/schema"
 	"durl/internal/scraper"
 	_ "durl/internal/sites/baidu"
 	_ "durl/internal/sites/bing"
 	generic "durl/internal/sites/generic"
 	_ "durl/internal/sites/xueqiu"
 
+	"github.com/go-rod/rod/lib/proto"
 	"github.com/spf13/cobra"
 )
 
@@ -29,7 +37,7 @@ var (
 	outputFormat string
 	outputFile   string
 	waitFor      string
-	waitTarget   string
+	waitTargets  []string
 	timeout      time.Duration
 	level        string
 	selector     string
@@ -39,16 +47,76 @@ var (
 	sort         string
 	showUI       bool
 	proxyURL     string
+	include      bool
+	headOnly     bool
+	failFast     bool
+	cookie       string
+	cookieJar    string
+	userDataDir  string
+	inputFile    string
+	concurrency  int
+	ignoreRobots bool
+	hostDelay    time.Duration
+	cacheTTL     time.Duration
+	noCache      bool
+	offline      bool
+	frontMatter  bool
+	schemaFile   string
+	actionsFile  string
+	paginateSpec string
+	dedupeKey    string
+	loginFile    string
+	pierce       bool
+	frames       bool
+	attrName     string
+	textOnly     bool
+	tableSpec    string
+	viewport     string
+	deviceScale  float64
+	paper        string
+	landscape    bool
+	harFile      string
+	harBodies    bool
+	block        []string
+	mockFile     string
+)
+
+// Exit codes; network, HTTP and timeout codes follow curl's numbering
+const (
+	exitOK               = 0
+	exitError            = 1  // usage, output or other unclassified error
+	exitResolve          = 6  // could not resolve host
+	exitNetwork          = 7  // connection or other network failure
+	exitHTTP             = 22 // HTTP status >= 400 (with --fail)
+	exitTimeout          = 28 // operation timed out
+	exitSelectorNotFound = 40 // css/xpath selector matched nothing (with --fail)
+	exitSiteFailure      = 41 // site-specific scraper failed
+	exitBlocked          = 42 // captcha or anti-bot challenge page
+	exitDisallowed       = 43 // skipped: disallowed by robots.txt (batch mode)
+	exitCacheMiss        = 44 // --offline and the request is not cached
 )
 
 func main() {
 	var rootCmd = &cobra.Command{
-		Use:     "durl [URL]",
+		Use:     "durl [URL...]",
 		Short:   "A curl-like tool with dynamic rendering support",
 		Version: version,
 		Long: `durl is a command-line tool similar to curl, but with support for
 dynamic web page rendering using Playwright. It can fetch and display
-content from pages that require JavaScript execution.`,
+content from pages that require JavaScript execution.
+
+Exit codes:
+  0   success
+  1   usage, output or other error
+  6   could not resolve host
+  7   network error
+  22  HTTP status >= 400 (with --fail)
+  28  timeout
+  40  selector matched nothing (with --fail)
+  41  site-specific scraper failed
+  42  blocked by captcha or anti-bot challenge
+  43  disallowed by robots.txt (batch mode)
+  44  not in cache (with --offline)`,
 		Example: `  # Extract content by CSS selector and save to file
   durl -l css -s ".stock-info-content" -o out.md https://xueqiu.com/snowman/S/SZ300454/detail#/GSLRB
 
@@ -70,11 +138,11 @@ content from pages that require JavaScript execution.`,
   # Search Baidu and get results
   durl --site baidu "golang tutorial" -f json`,
 		Args: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
+			if len(args) == 0 && inputFile == "" {
 				cmd.Help()
 				os.Exit(0)
 			}
-			return cobra.ExactArgs(1)(cmd, args)
+			return nil
 		},
 		RunE:         run,
 		SilenceUsage: true,
@@ -83,28 +151,60 @@ content from pages that require JavaScript execution.`,
 	rootCmd.Flags().StringVarP(&method, "method", "X", "GET", "HTTP method (GET, POST, PUT, DELETE, etc.)")
 	rootCmd.Flags().StringSliceVarP(&headers, "header", "H", []string{}, "HTTP headers (can be used multiple times)")
 	rootCmd.Flags().StringVarP(&data, "data", "d", "", "Request body data")
-	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format (html, text, markdown, json, csv)")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "Output format (html, text, markdown, json, csv, png, jpeg, pdf, mhtml, archive)")
 	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (format inferred from extension if -f not specified)")
-	rootCmd.Flags().StringVarP(&waitFor, "wait-for", "w", "load", "Wait strategy (load, element, time)")
-	rootCmd.Flags().StringVarP(&waitTarget, "wait-target", "T", "", "Wait target (selector for 'element' strategy, milliseconds for 'time' strategy)")
+	rootCmd.Flags().StringVarP(&waitFor, "wait-for", "w", generic.DefaultWait, "Wait strategy (load, networkidle, element, time, js, text, url), combined with '+' like element+networkidle")
+	rootCmd.Flags().StringArrayVarP(&waitTargets, "wait-target", "T", nil, "Wait target: selector (element), milliseconds (time), expression (js), text (text) or URL regex (url); repeat for composite strategies")
 	rootCmd.Flags().DurationVarP(&timeout, "timeout", "t", 30*time.Second, "Request timeout duration")
-	rootCmd.Flags().StringVarP(&level, "level", "l", "body", "Content extraction level (full, html, body, content, xpath, css)")
+	rootCmd.Flags().StringVarP(&level, "level", "l", "body", "Content extraction level (full, html, body, content, xpath, css, links)")
+	rootCmd.Flags().BoolVar(&pierce, "pierce", false, "CSS selectors and whole-page levels include open shadow DOM content")
+	rootCmd.Flags().BoolVar(&frames, "frames", false, "Selectors also search iframes; html/body/content/full levels inline iframe content")
 	rootCmd.Flags().StringVarP(&selector, "selector", "s", "", "Selector for xpath or css level")
+	rootCmd.Flags().StringVar(&attrName, "attr", "", "Output this attribute of each css/xpath match, one per line (JSON: array)")
+	rootCmd.Flags().BoolVar(&textOnly, "text", false, "Output the text of each css/xpath match, one per line (JSON: array)")
+	rootCmd.Flags().StringVar(&tableSpec, "table", "", "Output one table, by 1-based index or caption text, as rows (JSON: array of objects)")
 	rootCmd.Flags().StringVar(&site, "site", "", "Site-specific mode (e.g. xueqiu)")
 	rootCmd.Flags().StringVar(&last, "last", "30d", "time range: 7d, 1m, 1y, 202506, 2024")
 	rootCmd.Flags().IntVar(&maxPages, "max-pages", -1, "Max pages to paginate (-1 for no limit)")
+	rootCmd.Flags().StringVar(&paginateSpec, "paginate", "", "Harvest further pages: \"scroll\" (infinite scroll) or \"next=<selector>\" (generic mode)")
+	rootCmd.Flags().StringVar(&dedupeKey, "dedupe-key", "", "CSS selector inside each item whose href or text identifies repeated items across pages")
 	rootCmd.Flags().StringVar(&sort, "sort", "hot", "Sort order: hot or new")
 	rootCmd.Flags().BoolVar(&showUI, "showui", false, "Show browser UI (disable headless mode)")
 	rootCmd.Flags().StringVarP(&proxyURL, "proxy", "p", os.Getenv("DURL_PROXY"), "Proxy URL (e.g. http://127.0.0.1:7890), defaults to DURL_PROXY env var")
+	rootCmd.Flags().BoolVarP(&include, "include", "i", false, "Include response status line and headers in the output")
+	rootCmd.Flags().BoolVarP(&headOnly, "head", "I", false, "Show response status line and headers only (sends HEAD unless -X is given)")
+	rootCmd.Flags().StringVarP(&cookie, "cookie", "b", "", "Cookie file to load (Netscape or JSON), or a literal \"name=value; ...\" string")
+	rootCmd.Flags().StringVarP(&cookieJar, "cookie-jar", "c", "", "File to save cookies to after the request (.json for JSON, otherwise Netscape)")
+	rootCmd.Flags().StringVar(&loginFile, "login", "", "YAML/JSON login form definition run before the fetch (generic mode)")
+	rootCmd.Flags().StringVar(&userDataDir, "user-data-dir", "", "Persistent browser profile directory")
+	rootCmd.Flags().StringVar(&inputFile, "input-file", "", "Read targets from a file, one per line ('-' for stdin); no -i shorthand, which is --include")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", 4, "Number of targets fetched in parallel in batch mode")
+	rootCmd.Flags().BoolVar(&ignoreRobots, "ignore-robots", false, "Do not fetch or honour robots.txt in batch mode")
+	rootCmd.Flags().DurationVar(&hostDelay, "delay", time.Second, "Minimum delay between batch requests to the same host (robots.txt Crawl-delay may raise it)")
+	rootCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "Store responses and reuse them while younger than this instead of fetching (e.g. 10m)")
+	rootCmd.Flags().BoolVar(&noCache, "no-cache", false, "Neither read nor store the response cache")
+	rootCmd.Flags().BoolVar(&offline, "offline", false, "Serve responses from the cache only, regardless of age")
+	rootCmd.Flags().BoolVar(&frontMatter, "front-matter", false, "Prepend YAML front matter (title, URL, canonical, description, ...) to markdown output")
+	rootCmd.Flags().StringVar(&actionsFile, "actions", "", "YAML/JSON list of page actions (click, type, press, select, scroll, wait, eval, screenshot) run before extraction (generic mode)")
+	rootCmd.Flags().StringVar(&schemaFile, "schema", "", "YAML/JSON schema of fields to extract as structured records (generic mode)")
+	rootCmd.Flags().StringVar(&viewport, "viewport", "", "Browser viewport as WIDTHxHEIGHT (e.g. 1440x900)")
+	rootCmd.Flags().Float64Var(&deviceScale, "device-scale", 0, "Device scale factor (e.g. 2 for retina screenshots)")
+	rootCmd.Flags().StringVar(&paper, "paper", "a4", "PDF paper size (a3, a4, a5, letter, legal, tabloid)")
+	rootCmd.Flags().BoolVar(&landscape, "landscape", false, "Print PDF in landscape orientation")
+	rootCmd.Flags().StringVar(&harFile, "har", "", "Record all network requests of the session to a HAR file")
+	rootCmd.Flags().BoolVar(&harBodies, "har-bodies", false, "Include response bodies in the HAR file")
+	rootCmd.Flags().StringSliceVar(&block, "block", nil, "Block requests: resource types (image, font, media, stylesheet, ...), \"ads\" or URL globs (repeatable)")
+	rootCmd.Flags().StringVar(&mockFile, "mock", "", "YAML rules file that mocks, aborts or rewrites matching requests")
+	rootCmd.Flags().BoolVar(&failFast, "fail", false, "Fail on HTTP errors, empty selector matches and challenge pages (see exit codes)")
+
+	rootCmd.AddCommand(newCrawlCommand())
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 func run(cmd *cobra.Command, args []string) error {
-	target := args[0]
-
 	// If output file is specified but format is not, infer format from file extension
 	if outputFile != "" && outputFormat == "text" {
 		inferredFormat := inferFormatFromExtension(outputFile)
@@ -113,95 +213,436 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// -I behaves like curl: fetch headers only, using HEAD unless -X was given
+	if headOnly && !cmd.Flags().Changed("method") {
+		method = "HEAD"
+	}
+
 	if err := validateFlags(); err != nil {
 		return err
 	}
 
-	// Build scraper.Options
-	opts := scraper.Options{
-		Method:     method,
-		Headers:    parseHeaders(headers),
-		Body:       data,
-		WaitFor:    waitFor,
-		WaitTarget: waitTarget,
-		Timeout:    timeout,
-		Level:      level,
-		Selector:   selector,
-		ShowUI:     showUI,
-		ProxyURL:   proxyURL,
+	// All scrapers share warm browsers through the pool
+	pool := browser.NewPool()
+	defer pool.Close()
+
+	opts, err := buildOptions(pool)
+	if err != nil {
+		return err
+	}
+	// Written even when the scrape fails, which is when it is most useful
+	defer writeHAR(opts.HAR)
+
+	ctx := context.Background()
+
+	// Batch mode: several targets, an input file or stdin
+	if len(args) != 1 || inputFile != "" || args[0] == "-" {
+		opts.Politeness = politeness.New(politeness.Config{Interval: hostDelay, IgnoreRobots: ignoreRobots, ProxyURL: proxyURL})
+		return runBatch(ctx, args, opts)
+	}
+	target := args[0]
+
+	content, err := scrapeTarget(ctx, target, opts)
+	if err != nil {
+		return err
+	}
+
+	outputContent, err := renderContent(content)
+	if err != nil {
+		return err
+	}
+
+	// Output result
+	if outputFile != "" {
+		if err := os.WriteFile(outputFile, []byte(outputContent), 0644); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Output written to: %s\n", outputFile)
+	} else {
+		fmt.Println(outputContent)
+	}
+
+	// For non-JSON format and stdout output, output metadata to stderr (generic mode only)
+	if pc, ok := content.(generic.Content); ok && outputFormat != "json" && outputFile == "" && !headOnly {
+		fmt.Fprintf(os.Stderr, "\n%s", pc.Summary())
+	}
+
+	return nil
+}
+
+// buildOptions builds scraper.Options from the command-line flags
+func buildOptions(pool *browser.Pool) (scraper.Options, error) {
+	// -b accepts a cookie file, or curl's literal "name=value" form sent as a header
+	reqHeaders := parseHeaders(headers)
+	var cookies []*proto.NetworkCookieParam
+	if cookie != "" {
+		if _, statErr := os.Stat(cookie); statErr != nil && strings.Contains(cookie, "=") {
+			reqHeaders["Cookie"] = cookie
+		} else {
+			loaded, err := browser.LoadCookies(cookie)
+			if err != nil {
+				return scraper.Options{}, err
+			}
+			cookies = loaded
+		}
+	}
+
+	var fieldSchema *schema.Schema
+	if schemaFile != "" {
+		s, err := schema.Load(schemaFile)
+		if err != nil {
+			return scraper.Options{}, err
+		}
+		fieldSchema = s
+	}
+
+	var loginFlow *login.Flow
+	if loginFile != "" {
+		f, err := login.Load(loginFile)
+		if err != nil {
+			return scraper.Options{}, err
+		}
+		loginFlow = f
+	}
+
+	pagination, err := generic.ParsePagination(paginateSpec)
+	if err != nil {
+		return scraper.Options{}, err
+	}
+	pagination.Key = dedupeKey
+	pagination.MaxPages = maxPages
+
+	var script *actions.Script
+	if actionsFile != "" {
+		s, err := actions.Load(actionsFile)
+		if err != nil {
+			return scraper.Options{}, err
+		}
+		if err := generic.CheckActions(s); err != nil {
+			return scraper.Options{}, err
+		}
+		script = s
+	}
+
+	vp, err := parseViewport(viewport, deviceScale)
+	if err != nil {
+		return scraper.Options{}, err
+	}
+	blockRules, err := browser.ParseBlockRules(block)
+	if err != nil {
+		return scraper.Options{}, err
+	}
+	var mockRules *browser.MockRules
+	if mockFile != "" {
+		if mockRules, err = browser.LoadMockRules(mockFile); err != nil {
+			return scraper.Options{}, err
+		}
+	}
+	var capture scraper.Capture
+	if isCaptureFormat(outputFormat) {
+		capture = scraper.Capture{Format: outputFormat, Paper: paper, Landscape: landscape}
+	}
+
+	return scraper.Options{
+		Method:      method,
+		Headers:     reqHeaders,
+		Body:        data,
+		WaitFor:     waitFor,
+		WaitTargets: waitTargets,
+		Timeout:     timeout,
+		Level:       level,
+		Selector:    selector,
+		ShowUI:      showUI,
+		ProxyURL:    proxyURL,
 		Extra: map[string]string{
 			"last":      last,
 			"max-pages": strconv.Itoa(maxPages),
 			"sort":      sort,
 		},
+		Pool:        pool,
+		Fail:        failFast,
+		FrontMatter: frontMatter,
+		Schema:      fieldSchema,
+		Actions:     script,
+		Pagination:  pagination,
+		Pierce:      pierce,
+		Frames:      frames,
+		Attr:        attrName,
+		Text:        textOnly,
+		Table:       tableSpec,
+		Cookies:     cookies,
+		CookieJar:   cookieJar,
+		UserDataDir: userDataDir,
+		Login:       loginFlow,
+		Viewport:    vp,
+		Capture:     capture,
+		HAR:         harRecorder(),
+		Block:       blockRules,
+		Mock:        mockRules,
+		Cache: cache.New(cache.Config{
+			Dir:      os.Getenv("DURL_CACHE_DIR"),
+			TTL:      cacheTTL,
+			Disabled: noCache,
+			Offline:  offline,
+		}),
+	}, nil
+}
+
+// harRecorder returns the --har recorder, or nil when recording is off
+func harRecorder() *har.Recorder {
+	if harFile == "" {
+		return nil
 	}
+	return har.New(version, harBodies)
+}
 
-	ctx := context.Background()
+// writeHAR writes the recorded traffic to the --har file
+func writeHAR(rec *har.Recorder) {
+	if rec == nil {
+		return
+	}
+	if err := rec.WriteFile(harFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "HAR written to: %s\n", harFile)
+}
+
+// scrapeTarget returns the content for a single target, from the response
+// cache when a fresh entry exists (or --offline is set), else by fetching it
+func scrapeTarget(ctx context.Context, target string, opts scraper.Options) (scraper.Content, error) {
+	key := cache.Key{
+		Site:    site,
+		Method:  opts.Method,
+		URL:     target,
+		Extra:   opts.Extra,
+		Cookies: cache.Digest(opts.Cookies),
+		Profile: cache.Digest(absPath(opts.UserDataDir)),
+	}
+	if site == "" {
+		key = cache.Key{
+			Method:   opts.Method,
+			URL:      normalizeURL(target),
+			Headers:  cache.Digest(opts.Headers),
+			Body:     cache.Digest(opts.Body),
+			Cookies:  key.Cookies,
+			Profile:  key.Profile,
+			Wait:     cache.Digest([]any{opts.WaitFor, opts.WaitTargets}),
+			Level:    opts.Level,
+			Selector: opts.Selector,
+			Pierce:   opts.Pierce,
+			Frames:   opts.Frames,
+			Attr:     opts.Attr,
+			Text:     opts.Text,
+			Table:    opts.Table,
+		}
+		if opts.Schema != nil {
+			key.Schema = opts.Schema.Hash()
+		}
+		if opts.Capture.Format != "" || opts.Viewport.Width > 0 {
+			key.Capture = fmt.Sprintf("%+v %+v", opts.Capture, opts.Viewport)
+		}
+		if opts.Block != nil {
+			key.Block = opts.Block.Spec
+		}
+		if opts.Mock != nil {
+			key.Mock = opts.Mock.Hash()
+		}
+		if opts.Actions != nil {
+			key.Actions = opts.Actions.Hash()
+		}
+		if opts.Login != nil {
+			key.Login = opts.Login.Hash()
+		}
+		if opts.Pagination.Mode != "" {
+			key.Paginate = fmt.Sprintf("%+v", opts.Pagination)
+		}
+	}
+
+	if content, ok := cachedContent(opts.Cache, key); ok {
+		// Presentation options are not part of the cache key
+		if pc, ok := content.(generic.Content); ok {
+			pc.SetFrontMatter(opts.FrontMatter)
+		}
+		return content, nil
+	}
+	if opts.Cache.Offline() {
+		return nil, fmt.Errorf("%w: %s", cache.ErrMiss, target)
+	}
+
+	content, err := fetchTarget(ctx, target, opts)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := content.(scraper.Snapshotter); ok {
+		data, err := s.Snapshot()
+		if err == nil {
+			err = opts.Cache.Put(key, data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[cache] Warning: %v\n", err)
+		}
+	}
+	return content, nil
+}
+
+// absPath makes a path absolute so equivalent spellings share a cache key
+func absPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// cachedContent restores the cached content for key, if any
+func cachedContent(c *cache.Cache, key cache.Key) (scraper.Content, bool) {
+	data, storedAt, ok := c.Get(key)
+	if !ok {
+		return nil, false
+	}
 
-	var content scraper.Content
-	var err error
+	var restorer scraper.Restorer = &generic.GenericScraper{}
+	if site != "" {
+		s, _ := scraper.Get(site)
+		if restorer, ok = s.(scraper.Restorer); !ok {
+			return nil, false
+		}
+	}
+	content, err := restorer.Restore(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[cache] Warning: %v\n", err)
+		return nil, false
+	}
+	fmt.Fprintf(os.Stderr, "[cache] %s (cached %s ago)\n", key.URL, time.Since(storedAt).Round(time.Second))
+	return content, true
+}
 
+// fetchTarget scrapes a single target with the site scraper selected by
+// --site, or the generic scraper (with proxy retry) otherwise
+func fetchTarget(ctx context.Context, target string, opts scraper.Options) (scraper.Content, error) {
 	if site != "" {
 		// Site-specific mode: Get Scraper from registry
 		s, ok := scraper.Get(site)
 		if !ok {
-			return fmt.Errorf("unknown site: %s", site)
+			return nil, fmt.Errorf("unknown site: %s", site)
+		}
+		// Site queries are rate-limited by the page the scraper requests
+		if err := opts.Politeness.Wait(ctx, s.RequestURL(target)); err != nil {
+			return nil, err
 		}
-		content, err = s.Scrape(ctx, target, opts)
+		content, err := s.Scrape(ctx, target, opts)
 		if err != nil {
-			return fmt.Errorf("failed to scrape: %w", err)
+			return nil, fmt.Errorf("%w: %w", scraper.ErrSiteFailure, err)
 		}
-	} else {
-		// Generic mode: Use GenericScraper with proxy retry
-		target = normalizeURL(target)
-		gs := generic.NewGenericScraper(browser.Config{
-			Headless: !showUI,
-		})
-		content, err = gs.Scrape(ctx, target, opts)
+		return content, nil
+	}
+
+	target = normalizeURL(target)
+	if err := opts.Politeness.Wait(ctx, target); err != nil {
+		return nil, err
+	}
+
+	// Generic mode: Use GenericScraper with proxy retry
+	// A profile can only be open in one Chrome, so with --user-data-dir the
+	// proxy is used from the start instead of for a second launch
+	if opts.UserDataDir != "" {
+		content, err := generic.NewGenericScraper(opts.BrowserConfig()).Scrape(ctx, target, opts)
 		if err != nil {
-			// If failed and proxy is available, retry with proxy
-			if proxyURL != "" {
-				fmt.Fprintf(os.Stderr, "Warning: First attempt failed: %v\n", err)
-				fmt.Fprintf(os.Stderr, "Retrying with proxy: %s\n", proxyURL)
-				gs2 := generic.NewGenericScraper(browser.Config{
-					ProxyURL: proxyURL,
-					Headless: !showUI,
-				})
-				content, err = gs2.Scrape(ctx, target, opts)
-				if err != nil {
-					return fmt.Errorf("failed to fetch page (even with proxy): %w", err)
-				}
-				fmt.Fprintf(os.Stderr, "Fetched successfully (with proxy: %s)\n", proxyURL)
-			} else {
-				return fmt.Errorf("failed to fetch page: %w", err)
-			}
+			return nil, fmt.Errorf("failed to fetch page: %w", err)
 		}
+		return content, nil
+	}
+
+	// First attempt goes direct; the proxy is only used for the retry
+	cfg := opts.BrowserConfig()
+	cfg.ProxyURL = ""
+	gs := generic.NewGenericScraper(cfg)
+	content, err := gs.Scrape(ctx, target, opts)
+	if err == nil {
+		return content, nil
+	}
+
+	// If the network failed and proxy is available, retry with proxy; HTTP
+	// errors and challenge pages came from the site and would only repeat
+	if proxyURL == "" || !retryable(err) {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
 	}
+	fmt.Fprintf(os.Stderr, "Warning: First attempt failed: %v\n", err)
+	fmt.Fprintf(os.Stderr, "Retrying with proxy: %s\n", proxyURL)
+	gs2 := generic.NewGenericScraper(opts.BrowserConfig())
+	content, err = gs2.Scrape(ctx, target, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page (even with proxy): %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Fetched successfully (with proxy: %s)\n", proxyURL)
+	return content, nil
+}
 
-	// Format output
+// retryable reports whether a failed fetch may succeed through the proxy:
+// network errors and timeouts
+func retryable(err error) bool {
+	var netErr *scraper.NetworkError
+	return errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// renderContent formats content according to --format, --include and --head
+func renderContent(content scraper.Content) (string, error) {
+	if headOnly {
+		return responseHeaderText(content), nil
+	}
 	outputContent, err := formatter.Format(content, outputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to format output: %w", err)
+		return "", fmt.Errorf("failed to format output: %w", err)
 	}
+	if include {
+		outputContent = responseHeaderText(content) + "\n" + outputContent
+	}
+	return outputContent, nil
+}
 
-	// Output result
-	if outputFile != "" {
-		if err := os.WriteFile(outputFile, []byte(outputContent), 0644); err != nil {
-			return fmt.Errorf("failed to write to file: %w", err)
+// exitCode maps an error returned by run to the documented exit code.
+// More specific causes win over the generic site-scraper failure.
+func exitCode(err error) int {
+	var netErr *scraper.NetworkError
+	var httpErr *scraper.HTTPError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, politeness.ErrDisallowed):
+		return exitDisallowed
+	case errors.Is(err, cache.ErrMiss):
+		return exitCacheMiss
+	case errors.Is(err, scraper.ErrBlocked):
+		return exitBlocked
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.As(err, &netErr):
+		if strings.Contains(netErr.Reason, "ERR_NAME_NOT_RESOLVED") {
+			return exitResolve
 		}
-		fmt.Fprintf(os.Stderr, "Output written to: %s\n", outputFile)
-	} else {
-		fmt.Println(outputContent)
+		return exitNetwork
+	case errors.As(err, &httpErr):
+		return exitHTTP
+	case errors.Is(err, scraper.ErrSelectorNotFound):
+		return exitSelectorNotFound
+	case errors.Is(err, scraper.ErrSiteFailure):
+		return exitSiteFailure
+	default:
+		return exitError
 	}
+}
 
-	// For non-JSON format and stdout output, output metadata to stderr (generic mode only)
-	if site == "" && outputFormat != "json" && outputFile == "" {
-		// In generic mode, content is *generic.PageContent which contains metadata
-		// Get metadata via ToJSON (simplified: output separator directly)
-		fmt.Fprintf(os.Stderr, "\n---\n")
+// responseHeaderText returns the curl-style status line and headers of the
+// main document (generic mode only)
+func responseHeaderText(content scraper.Content) string {
+	pc, ok := content.(generic.Content)
+	if !ok {
+		return ""
 	}
-
-	return nil
+	return pc.Response().HeaderText()
 }
 
 func validateFlags() error {
@@ -224,26 +665,18 @@ func validateFlags() error {
 		"markdown": true,
 		"json":     true,
 		"csv":      true,
+		"png":      true,
+		"jpeg":     true,
+		"pdf":      true,
+		"mhtml":    true,
+		"archive":  true,
 	}
 	if !validFormats[outputFormat] {
 		return fmt.Errorf("invalid output format: %s", outputFormat)
 	}
 
-	validStrategies := map[string]bool{
-		"load":    true,
-		"element": true,
-		"time":    true,
-	}
-	if !validStrategies[waitFor] {
-		return fmt.Errorf("invalid wait strategy: %s", waitFor)
-	}
-
-	if waitFor == "element" && waitTarget == "" {
-		return fmt.Errorf("--wait-target is required when using 'element' wait strategy")
-	}
-
-	if waitFor == "time" && waitTarget == "" {
-		return fmt.Errorf("--wait-target is required when using 'time' wait strategy")
+	if _, err := generic.ParseWait(waitFor, waitTargets); err != nil {
+		return err
 	}
 
 	validLevels := map[string]bool{
@@ -253,6 +686,7 @@ func validateFlags() error {
 		"content": true,
 		"xpath":   true,
 		"css":     true,
+		"links":   true,
 	}
 	if !validLevels[level] {
 		return fmt.Errorf("invalid content level: %s", level)
@@ -266,6 +700,92 @@ func validateFlags() error {
 		return fmt.Errorf("--selector is only valid with 'xpath' or 'css' level")
 	}
 
+	if attrName != "" || textOnly {
+		switch {
+		case attrName != "" && textOnly:
+			return fmt.Errorf("--attr and --text cannot be used together")
+		case site != "":
+			return fmt.Errorf("--attr and --text are only valid in generic mode")
+		case schemaFile != "":
+			return fmt.Errorf("--attr and --text cannot be used with --schema")
+		case level != "css" && level != "xpath":
+			return fmt.Errorf("--attr and --text require the css or xpath level")
+		}
+	}
+
+	if tableSpec != "" {
+		switch {
+		case site != "":
+			return fmt.Errorf("--table is only valid in generic mode")
+		case schemaFile != "":
+			return fmt.Errorf("--table cannot be used with --schema")
+		case level == "links":
+			return fmt.Errorf("--table cannot be used with the links level")
+		case attrName != "" || textOnly:
+			return fmt.Errorf("--table cannot be used with --attr or --text")
+		case paginateSpec != "":
+			return fmt.Errorf("--table cannot be used with --paginate")
+		}
+	}
+
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	if offline && noCache {
+		return fmt.Errorf("--offline and --no-cache cannot be used together")
+	}
+
+	if actionsFile != "" {
+		if site != "" {
+			return fmt.Errorf("--actions is only valid in generic mode")
+		}
+	}
+
+	if loginFile != "" && site != "" {
+		return fmt.Errorf("--login is only valid in generic mode")
+	}
+
+	if paginateSpec != "" && site != "" {
+		return fmt.Errorf("--paginate is only valid in generic mode")
+	}
+
+	if dedupeKey != "" {
+		switch {
+		case paginateSpec == "":
+			return fmt.Errorf("--dedupe-key requires --paginate")
+		case schemaFile == "" && level != "css" && level != "xpath":
+			return fmt.Errorf("--dedupe-key requires the css or xpath level or --schema")
+		}
+	}
+
+	if schemaFile != "" && site != "" {
+		return fmt.Errorf("--schema is only valid in generic mode")
+	}
+
+	if (include || headOnly) && site != "" {
+		return fmt.Errorf("--include and --head are only valid in generic mode")
+	}
+
+	if isCaptureFormat(outputFormat) {
+		switch {
+		case site != "":
+			return fmt.Errorf("-f %s is only valid in generic mode", outputFormat)
+		case outputFile == "" && isBinaryFormat(outputFormat):
+			return fmt.Errorf("-f %s requires --output", outputFormat)
+		case include || headOnly:
+			return fmt.Errorf("--include and --head cannot be used with -f %s", outputFormat)
+		}
+	}
+
+	if harBodies && harFile == "" {
+		return fmt.Errorf("--har-bodies requires --har")
+	}
+
+	if !generic.ValidPaper(paper) {
+		return fmt.Errorf("invalid paper size: %s", paper)
+	}
+
 	return nil
 }
 
@@ -275,7 +795,7 @@ func inferFormatFromExtension(filename string) string {
 	switch ext {
 	case ".md", ".markdown":
 		return "markdown"
-	case ".json":
+	case ".json", ".ndjson", ".jsonl":
 		return "json"
 	case ".html", ".htm":
 		return "html"
@@ -283,11 +803,51 @@ func inferFormatFromExtension(filename string) string {
 		return "text"
 	case ".csv":
 		return "csv"
+	case ".png":
+		return "png"
+	case ".jpg", ".jpeg":
+		return "jpeg"
+	case ".pdf":
+		return "pdf"
+	case ".mhtml", ".mht":
+		return "mhtml"
 	default:
 		return ""
 	}
 }
 
+// isCaptureFormat reports whether format is taken from the live page rather
+// than rendered from extracted content
+func isCaptureFormat(format string) bool {
+	return isBinaryFormat(format) || format == "mhtml" || format == "archive"
+}
+
+// isBinaryFormat reports whether format is a binary capture (png, jpeg, pdf)
+func isBinaryFormat(format string) bool {
+	return format == "png" || format == "jpeg" || format == "pdf"
+}
+
+// parseViewport parses --viewport WIDTHxHEIGHT and --device-scale. A scale
+// without a viewport applies to the default 1280x800 window.
+func parseViewport(size string, scale float64) (browser.Viewport, error) {
+	if scale < 0 {
+		return browser.Viewport{}, fmt.Errorf("--device-scale must not be negative")
+	}
+	if size == "" {
+		if scale == 0 {
+			return browser.Viewport{}, nil
+		}
+		return browser.Viewport{Width: 1280, Height: 800, Scale: scale}, nil
+	}
+	w, h, ok := strings.Cut(strings.ToLower(size), "x")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return browser.Viewport{}, fmt.Errorf("invalid --viewport %q (want WIDTHxHEIGHT)", size)
+	}
+	return browser.Viewport{Width: width, Height: height, Scale: scale}, nil
+}
+
 // parseHeaders parses request header parameters
 func parseHeaders(headerSlice []string) map[string]string {
 	headersMap := make(map[string]string)